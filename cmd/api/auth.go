@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"financial_assistance/internal/config"
+	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository/postgres"
+	"financial_assistance/pkg/database"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	usersUsage = "usage: api users create [--role role] <username> | passwd | disable | enable <username> | role <username> <role>"
+	keysUsage  = "usage: api keys create [--role role] <name> | list | revoke <id>"
+)
+
+// runAuthCommand manages staff users and API keys. Passwords are read from
+// the first line of standard input so that they stay out of the shell
+// history and process list.
+func runAuthCommand(cfg *config.Config, command string, args []string) error {
+	usage := usersUsage
+	if command == "keys" {
+		usage = keysUsage
+	}
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	if cfg.Storage != "postgres" {
+		return fmt.Errorf("%s needs postgres storage, configured storage is %q", command, cfg.Storage)
+	}
+
+	db, err := database.NewConnection(cfg.Database.ConnectionConfig())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	authenticator := newAuthenticator(cfg, postgres.NewUserRepo(db), postgres.NewAPIKeyRepo(db))
+	ctx := context.Background()
+
+	// Only create takes a role; new accounts and keys get the least
+	// privileged role that can still do casework.
+	role := models.RoleCaseworker
+	if args[0] == "create" {
+		var names []string
+		role, names, err = parseCreateArgs(command, args[1:])
+		if err != nil {
+			return fmt.Errorf("%v\n%s", err, usage)
+		}
+		args = append(args[:1], names...)
+	}
+
+	switch command + " " + args[0] {
+	case "users create", "users passwd":
+		if len(args) != 2 {
+			return errors.New(usage)
+		}
+		password, err := readPassword()
+		if err != nil {
+			return err
+		}
+		if args[0] == "create" {
+			user, err := authenticator.CreateUser(ctx, args[1], password, role)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("created %s %s (%s)\n", user.Role, user.Username, user.ID)
+			return nil
+		}
+		if err := authenticator.SetPassword(ctx, args[1], password); err != nil {
+			return err
+		}
+		fmt.Printf("changed password of %s\n", args[1])
+	case "users disable", "users enable":
+		if len(args) != 2 {
+			return errors.New(usage)
+		}
+		if err := authenticator.SetDisabled(ctx, args[1], args[0] == "disable"); err != nil {
+			return err
+		}
+		fmt.Printf("%sd user %s\n", args[0], args[1])
+	case "users role":
+		if len(args) != 3 {
+			return errors.New(usage)
+		}
+		role := models.Role(models.NormalizeEnum(args[2]))
+		if err := authenticator.SetRole(ctx, args[1], role); err != nil {
+			return err
+		}
+		fmt.Printf("%s is now %s\n", args[1], role)
+	case "keys create":
+		if len(args) != 2 {
+			return errors.New(usage)
+		}
+		plain, key, err := authenticator.CreateAPIKey(ctx, args[1], role)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "created %s API key %s (%s); it is shown only once:\n", key.Role, key.Name, key.ID)
+		fmt.Println(plain)
+	case "keys list":
+		if len(args) != 1 {
+			return errors.New(usage)
+		}
+		keys, err := authenticator.ListAPIKeys(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tROLE\tPREFIX\tCREATED AT\tREVOKED AT")
+		for _, k := range keys {
+			revokedAt := "-"
+			if k.RevokedAt != nil {
+				revokedAt = k.RevokedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", k.ID, k.Name, k.Role, k.Prefix, k.CreatedAt.Format(time.RFC3339), revokedAt)
+		}
+		return w.Flush()
+	case "keys revoke":
+		if len(args) != 2 {
+			return errors.New(usage)
+		}
+		id, err := uuid.Parse(args[1])
+		if err != nil {
+			return fmt.Errorf("revoke: invalid key ID %q", args[1])
+		}
+		if err := authenticator.RevokeAPIKey(ctx, id); err != nil {
+			return err
+		}
+		fmt.Printf("revoked API key %s\n", id)
+	default:
+		return errors.New(usage)
+	}
+
+	return nil
+}
+
+// parseCreateArgs parses the arguments of users create and keys create. The
+// flag package stops at the first name, so flags are parsed again after each
+// one: both "create --role admin alice" and "create alice --role admin" work.
+// Arguments after "--" are all names.
+func parseCreateArgs(command string, args []string) (models.Role, []string, error) {
+	role := models.RoleCaseworker
+	flags := flag.NewFlagSet(command+" create", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.Var((*roleFlag)(&role), "role", "")
+
+	var names []string
+	for len(args) > 0 {
+		if err := flags.Parse(args); err != nil {
+			return "", nil, err
+		}
+		rest := flags.Args()
+		if parsed := len(args) - len(rest); parsed > 0 && args[parsed-1] == "--" {
+			names = append(names, rest...)
+			break
+		}
+		if len(rest) == 0 {
+			break
+		}
+		names = append(names, rest[0])
+		args = rest[1:]
+	}
+	return role, names, nil
+}
+
+func readPassword() (string, error) {
+	fmt.Fprint(os.Stderr, "password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	fmt.Fprintln(os.Stderr)
+	if err != nil && line == "" {
+		return "", fmt.Errorf("reading password: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// roleFlag parses --role into a models.Role.
+type roleFlag models.Role
+
+func (f *roleFlag) String() string { return string(*f) }
+
+func (f *roleFlag) Set(value string) error {
+	role := models.Role(models.NormalizeEnum(value))
+	if !role.Valid() {
+		return fmt.Errorf("invalid role %q", value)
+	}
+	*f = roleFlag(role)
+	return nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"financial_assistance/internal/models"
+	"slices"
+	"testing"
+)
+
+func TestParseCreateArgs(t *testing.T) {
+	tests := []struct {
+		args  []string
+		role  models.Role
+		names []string
+	}{
+		{[]string{"alice"}, models.RoleCaseworker, []string{"alice"}},
+		{[]string{"--role", "admin", "alice"}, models.RoleAdmin, []string{"alice"}},
+		{[]string{"alice", "--role", "admin"}, models.RoleAdmin, []string{"alice"}},
+		{[]string{"alice", "--role=Auditor"}, models.RoleAuditor, []string{"alice"}},
+		{[]string{"-role", "auditor", "alice", "extra"}, models.RoleAuditor, []string{"alice", "extra"}},
+		{[]string{"alice", "--role", "admin", "extra"}, models.RoleAdmin, []string{"alice", "extra"}},
+		{[]string{"--", "--role", "admin"}, models.RoleCaseworker, []string{"--role", "admin"}},
+		{[]string{"--role", "admin", "alice", "--", "-x"}, models.RoleAdmin, []string{"alice", "-x"}},
+		{nil, models.RoleCaseworker, nil},
+	}
+	for _, tt := range tests {
+		role, names, err := parseCreateArgs("users", tt.args)
+		if err != nil || role != tt.role || !slices.Equal(names, tt.names) {
+			t.Errorf("parseCreateArgs(%q) = %s, %q, %v; want %s, %q", tt.args, role, names, err, tt.role, tt.names)
+		}
+	}
+}
+
+func TestParseCreateArgsRejects(t *testing.T) {
+	for _, args := range [][]string{
+		{"alice", "--role", "superuser"},
+		{"alice", "--role"},
+		{"alice", "--admin"},
+	} {
+		if role, names, err := parseCreateArgs("users", args); err == nil {
+			t.Errorf("parseCreateArgs(%q) = %s, %q; want an error", args, role, names)
+		}
+	}
+}
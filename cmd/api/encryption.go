@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"financial_assistance/internal/config"
+	"financial_assistance/internal/encryption"
+	"financial_assistance/internal/repository/postgres"
+	"financial_assistance/pkg/database"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	encryptionUsage = "usage: api encryption rotate | status"
+
+	reencryptBatchSize = 100
+
+	// Rows locked by concurrent updates are retried this often, up to
+	// reencryptMaxRetries times in a row, before giving up.
+	reencryptRetryDelay = time.Second
+	reencryptMaxRetries = 30
+)
+
+// runEncryption re-encrypts personal data with the active key of the
+// keyring, or reports which keys it is sealed with.
+func runEncryption(cfg *config.Config, args []string) error {
+	if len(args) != 1 || (args[0] != "rotate" && args[0] != "status") {
+		return errors.New(encryptionUsage)
+	}
+	if cfg.Storage != "postgres" {
+		return fmt.Errorf("encryption needs postgres storage, configured storage is %q", cfg.Storage)
+	}
+
+	keyring, err := encryption.LoadKeyring(cfg.Encryption.KeyringFile)
+	if err != nil {
+		return err
+	}
+	db, err := database.NewConnection(cfg.Database.ConnectionConfig())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	repo := postgres.NewApplicantRepo(db, keyring)
+	ctx := context.Background()
+
+	if args[0] == "rotate" {
+		total, retries := 0, 0
+		for {
+			n, remaining, err := repo.Reencrypt(ctx, reencryptBatchSize)
+			total += n
+			if err != nil {
+				return fmt.Errorf("re-encrypted %d row(s) before failing: %w", total, err)
+			}
+			if remaining == 0 {
+				break
+			}
+			if n > 0 {
+				retries = 0
+				continue
+			}
+			if retries++; retries > reencryptMaxRetries {
+				return fmt.Errorf("re-encrypted %d row(s), but %d stayed locked; run rotate again", total, remaining)
+			}
+			time.Sleep(reencryptRetryDelay)
+		}
+		fmt.Printf("re-encrypted %d row(s) with key %s\n", total, keyring.ActiveKeyID())
+		return nil
+	}
+
+	usage, err := repo.KeyUsage(ctx)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TABLE\tKEY\tROWS")
+	for _, u := range usage {
+		keyID := u.KeyID
+		switch {
+		case keyID == "":
+			keyID = "(plaintext)"
+		case keyID == keyring.ActiveKeyID():
+			keyID += " (active)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\n", u.Table, keyID, u.Rows)
+	}
+	return w.Flush()
+}
+
+// warnUnsealed logs a warning at startup if personal data is still in
+// plaintext or sealed with a key other than the active one.
+func warnUnsealed(repo *postgres.ApplicantRepo, activeKeyID string) {
+	usage, err := repo.KeyUsage(context.Background())
+	if err != nil {
+		slog.Warn("Could not check encryption status", "error", err)
+		return
+	}
+
+	pending := 0
+	for _, u := range usage {
+		if u.KeyID != activeKeyID {
+			pending += u.Rows
+		}
+	}
+	if pending > 0 {
+		slog.Warn("Rows are not sealed with the active key; run \"encryption rotate\"", "count", pending)
+	}
+}
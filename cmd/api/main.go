@@ -1,52 +1,223 @@
 package main
 
 import (
+	"context"
+	"financial_assistance/internal/auth"
+	"financial_assistance/internal/config"
+	"financial_assistance/internal/encryption"
 	"financial_assistance/internal/handler"
+	"financial_assistance/internal/logging"
+	"financial_assistance/internal/migrations"
+	"financial_assistance/internal/repository"
+	"financial_assistance/internal/repository/memory"
 	"financial_assistance/internal/repository/postgres"
 	"financial_assistance/internal/service"
 	"financial_assistance/pkg/database"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
 func main() {
-	dbConfig := &database.Config{
-		Host:     "localhost",
-		Port:     5433,
-		User:     "postgres",
-		Password: "aresh",
-		DBName:   "Tutorial1",
-		SSLMode:  "disable",
+	cfg, err := config.Load(commandSettings(os.Args[1:]))
+	if err != nil {
+		fatal("Could not load configuration", err)
 	}
 
-	db, err := database.NewConnection(dbConfig)
-	if err != nil {
-		log.Fatalf("Could not initialize database connection: %v", err)
+	logger := logging.New(os.Stderr, cfg.Log)
+	slog.SetDefault(logger)
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			fatal("Migration failed", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "encryption" {
+		if err := runEncryption(cfg, os.Args[2:]); err != nil {
+			fatal("Encryption failed", err)
+		}
+		return
 	}
-	defer db.Close()
+	if len(os.Args) > 1 && (os.Args[1] == "users" || os.Args[1] == "keys") {
+		if err := runAuthCommand(cfg, os.Args[1], os.Args[2:]); err != nil {
+			fatal("Command failed", err, "command", os.Args[1])
+		}
+		return
+	}
+
+	var (
+		applicantRepo   repository.ApplicantRepository
+		schemeRepo      repository.SchemeRepository
+		applicationRepo repository.ApplicationRepository
+		idempotencyRepo repository.IdempotencyRepository
+		userRepo        repository.UserRepository
+		apiKeyRepo      repository.APIKeyRepository
+	)
+
+	switch cfg.Storage {
+	case "memory":
+		logger.Info("Using in-memory storage")
+		store := memory.NewStore()
+		applicantRepo = memory.NewApplicantRepo(store)
+		schemeRepo = memory.NewSchemeRepo(store)
+		applicationRepo = memory.NewApplicationRepo(store)
+		idempotencyRepo = memory.NewIdempotencyRepo(store)
+		userRepo = memory.NewUserRepo(store)
+		apiKeyRepo = memory.NewAPIKeyRepo(store)
+	case "postgres":
+		db, err := database.NewConnection(cfg.Database.ConnectionConfig())
+		if err != nil {
+			fatal("Could not initialize database connection", err)
+		}
+		defer db.Close()
+
+		migrator, err := migrations.NewMigrator(db)
+		if err != nil {
+			fatal("Could not load migrations", err)
+		}
+		if cfg.Database.MigrateOnStart {
+			applied, err := migrator.Up(context.Background())
+			if err != nil {
+				fatal("Could not migrate database", err)
+			}
+			logger.Info("Applied migrations", "count", len(applied))
+		} else if pending, err := migrator.Pending(context.Background()); err != nil {
+			fatal("Could not read migration status", err)
+		} else if pending > 0 {
+			logger.Warn("Pending migrations; run \"migrate up\"", "count", pending)
+		}
 
-	applicantRepo := postgres.NewApplicantRepo(db)
-	schemeRepo := postgres.NewSchemeRepo(db)
-	applicationRepo := postgres.NewApplicationRepo(db)
+		keyring, err := encryption.LoadKeyring(cfg.Encryption.KeyringFile)
+		if err != nil {
+			fatal("Could not load encryption keyring", err)
+		}
+		postgresApplicants := postgres.NewApplicantRepo(db, keyring)
+		warnUnsealed(postgresApplicants, keyring.ActiveKeyID())
+
+		applicantRepo = postgresApplicants
+		schemeRepo = postgres.NewSchemeRepo(db)
+		applicationRepo = postgres.NewApplicationRepo(db)
+		idempotencyRepo = postgres.NewIdempotencyRepo(db)
+		userRepo = postgres.NewUserRepo(db)
+		apiKeyRepo = postgres.NewAPIKeyRepo(db)
+	}
+
+	go expireIdempotencyKeys(idempotencyRepo, time.Hour)
+
+	authenticator := newAuthenticator(cfg, userRepo, apiKeyRepo)
+	if cfg.Auth.BootstrapUser != "" {
+		created, err := authenticator.EnsureUser(context.Background(), cfg.Auth.BootstrapUser, cfg.Auth.BootstrapPassword)
+		if err != nil {
+			fatal("Could not create bootstrap user", err)
+		}
+		if created {
+			logger.Info("Created bootstrap user", "username", cfg.Auth.BootstrapUser)
+		}
+	}
 
 	svc := service.NewService(applicantRepo, schemeRepo, applicationRepo)
 
 	h := handler.NewHandler(svc)
+	ah := handler.NewAuthHandler(authenticator)
+	idem := handler.NewIdempotency(idempotencyRepo, time.Duration(cfg.Idempotency.TTL))
 
 	r := mux.NewRouter()
+	r.Use(handler.LogRequests(logger))
+	r.HandleFunc("/api/auth/login", ah.Login).Methods("POST")
+
+	// Every other route requires a staff token or an API key.
+	api := r.NewRoute().Subrouter()
+	api.Use(ah.Authenticate)
+
+	api.HandleFunc("/api/auth/me", ah.Me).Methods("GET")
 
-	r.HandleFunc("/api/applicants", h.GetAllApplicants).Methods("GET")
-	r.HandleFunc("/api/applicants", h.CreateApplicant).Methods("POST")
-	r.HandleFunc("/api/schemes", h.GetAllSchemes).Methods("GET")
-	r.HandleFunc("/api/schemes", h.CreateScheme).Methods("POST")
-	r.HandleFunc("/api/schemes/eligible", h.GetEligibleSchemes).Methods("GET")
-	r.HandleFunc("/api/applications", h.GetAllApplications).Methods("GET")
-	r.HandleFunc("/api/applications", h.CreateApplication).Methods("POST")
+	api.HandleFunc("/api/applicants", h.GetAllApplicants).Methods("GET")
+	api.Handle("/api/applicants", idem.Wrap(http.HandlerFunc(h.CreateApplicant))).Methods("POST")
+	api.HandleFunc("/api/applicants/{id}", h.GetApplicant).Methods("GET")
+	api.HandleFunc("/api/applicants/{id}", h.UpdateApplicant).Methods("PUT")
+	api.HandleFunc("/api/applicants/{id}", h.PatchApplicant).Methods("PATCH")
+	api.HandleFunc("/api/applicants/{id}", h.DeleteApplicant).Methods("DELETE")
+	api.HandleFunc("/api/applicants/{id}/household", h.GetHousehold).Methods("GET")
+	api.HandleFunc("/api/applicants/{id}/household", h.AddHouseholdMember).Methods("POST")
+	api.HandleFunc("/api/applicants/{id}/household/{memberID}", h.GetHouseholdMember).Methods("GET")
+	api.HandleFunc("/api/applicants/{id}/household/{memberID}", h.UpdateHouseholdMember).Methods("PUT")
+	api.HandleFunc("/api/applicants/{id}/household/{memberID}", h.DeleteHouseholdMember).Methods("DELETE")
+	api.HandleFunc("/api/schemes", h.GetAllSchemes).Methods("GET")
+	api.Handle("/api/schemes", idem.Wrap(http.HandlerFunc(h.CreateScheme))).Methods("POST")
+	api.HandleFunc("/api/schemes/eligible", h.GetEligibleSchemes).Methods("GET")
+	api.HandleFunc("/api/schemes/{id}", h.GetScheme).Methods("GET")
+	api.HandleFunc("/api/schemes/{id}", h.UpdateScheme).Methods("PUT")
+	api.HandleFunc("/api/schemes/{id}", h.DeleteScheme).Methods("DELETE")
+	api.HandleFunc("/api/schemes/{id}/criteria", h.UpdateCriteria).Methods("PUT")
+	api.HandleFunc("/api/schemes/{id}/benefits", h.AddBenefit).Methods("POST")
+	api.HandleFunc("/api/schemes/{id}/benefits/{benefitID}", h.GetBenefit).Methods("GET")
+	api.HandleFunc("/api/schemes/{id}/benefits/{benefitID}", h.DeleteBenefit).Methods("DELETE")
+	api.HandleFunc("/api/applications", h.GetAllApplications).Methods("GET")
+	api.Handle("/api/applications", idem.Wrap(http.HandlerFunc(h.CreateApplication))).Methods("POST")
+	api.HandleFunc("/api/applications/{id}", h.GetApplication).Methods("GET")
+	api.HandleFunc("/api/applications/{id}/transitions", h.TransitionApplication).Methods("POST")
+	api.HandleFunc("/api/applications/{id}/history", h.GetApplicationHistory).Methods("GET")
 
-	log.Printf("Starting server")
-	if err := http.ListenAndServe(":8080", r); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+	srv := &http.Server{
+		Addr:              cfg.HTTP.Addr,
+		Handler:           r,
+		ReadTimeout:       time.Duration(cfg.HTTP.ReadTimeout),
+		ReadHeaderTimeout: time.Duration(cfg.HTTP.ReadHeaderTimeout),
+		WriteTimeout:      time.Duration(cfg.HTTP.WriteTimeout),
+		IdleTimeout:       time.Duration(cfg.HTTP.IdleTimeout),
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelError),
 	}
+
+	logger.Info("Starting server", "addr", cfg.HTTP.Addr)
+	if err := srv.ListenAndServe(); err != nil {
+		fatal("Server failed to start", err)
+	}
+}
+
+// commandSettings returns the settings the command named by args needs
+// besides the database: migrations and account management need none of the
+// server's, and serving needs them all.
+func commandSettings(args []string) config.Settings {
+	if len(args) == 0 {
+		return config.AllSettings
+	}
+	switch args[0] {
+	case "migrate", "users", "keys":
+		return 0
+	case "encryption":
+		return config.EncryptionSettings
+	}
+	return config.AllSettings
+}
+
+// fatal logs err with the default logger and exits.
+func fatal(msg string, err error, args ...any) {
+	slog.Error(msg, append(args, "error", err)...)
+	os.Exit(1)
+}
+
+// expireIdempotencyKeys periodically deletes expired idempotency records.
+func expireIdempotencyKeys(repo repository.IdempotencyRepository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		deleted, err := repo.DeleteExpiredIdempotencyKeys(context.Background(), time.Now().UTC())
+		if err != nil {
+			slog.Error("Could not delete expired idempotency keys", "error", err)
+			continue
+		}
+		if deleted > 0 {
+			slog.Info("Deleted expired idempotency keys", "count", deleted)
+		}
+	}
+}
+
+func newAuthenticator(cfg *config.Config, users repository.UserRepository, keys repository.APIKeyRepository) *auth.Authenticator {
+	tokens := auth.NewTokenIssuer([]byte(cfg.Auth.JWTSecret), cfg.Auth.Issuer, time.Duration(cfg.Auth.TokenTTL))
+	return auth.NewAuthenticator(users, keys, tokens)
 }
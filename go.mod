@@ -8,3 +8,9 @@ require (
 )
 
 require github.com/lib/pq v1.10.9
+
+require (
+	github.com/golang-jwt/jwt/v5 v5.3.1
+	golang.org/x/crypto v0.45.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,145 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindValidation
+	KindUnauthenticated
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindUnprocessable
+	KindPreconditionFailed
+	KindPreconditionRequired
+	KindPayloadTooLarge
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindValidation:
+		return "validation"
+	case KindUnauthenticated:
+		return "unauthenticated"
+	case KindForbidden:
+		return "forbidden"
+	case KindNotFound:
+		return "not_found"
+	case KindConflict:
+		return "conflict"
+	case KindUnprocessable:
+		return "unprocessable"
+	case KindPreconditionFailed:
+		return "precondition_failed"
+	case KindPreconditionRequired:
+		return "precondition_required"
+	case KindPayloadTooLarge:
+		return "payload_too_large"
+	default:
+		return "internal"
+	}
+}
+
+// Error is a failure reported to API clients. Kind decides the HTTP status
+// and Code is a stable identifier clients can match on.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+
+	// Extensions are extra members included in the problem response, such
+	// as the criteria an applicant failed.
+	Extensions map[string]any
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is matches errors of the same kind and code, so that a sentinel compares
+// equal to copies made with With.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Code == e.Code
+}
+
+// With returns a copy of e carrying an extra problem member.
+func (e *Error) With(key string, value any) *Error {
+	c := *e
+	c.Extensions = make(map[string]any, len(e.Extensions)+1)
+	for k, v := range e.Extensions {
+		c.Extensions[k] = v
+	}
+	c.Extensions[key] = value
+	return &c
+}
+
+func New(kind Kind, code, format string, args ...any) *Error {
+	return &Error{Kind: kind, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func Validation(code, format string, args ...any) *Error {
+	return New(KindValidation, code, format, args...)
+}
+
+func Unauthenticated(code, format string, args ...any) *Error {
+	return New(KindUnauthenticated, code, format, args...)
+}
+
+func Forbidden(code, format string, args ...any) *Error {
+	return New(KindForbidden, code, format, args...)
+}
+
+func NotFound(code, format string, args ...any) *Error {
+	return New(KindNotFound, code, format, args...)
+}
+
+func Conflict(code, format string, args ...any) *Error {
+	return New(KindConflict, code, format, args...)
+}
+
+func Unprocessable(code, format string, args ...any) *Error {
+	return New(KindUnprocessable, code, format, args...)
+}
+
+func PreconditionFailed(code, format string, args ...any) *Error {
+	return New(KindPreconditionFailed, code, format, args...)
+}
+
+func PreconditionRequired(code, format string, args ...any) *Error {
+	return New(KindPreconditionRequired, code, format, args...)
+}
+
+func PayloadTooLarge(code, format string, args ...any) *Error {
+	return New(KindPayloadTooLarge, code, format, args...)
+}
+
+// As returns the first *Error in err's chain, or nil if there is none.
+func As(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return nil
+}
+
+// FieldError describes one invalid field of a request body.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Fields returns a validation error listing every field error, or nil if
+// there are none.
+func Fields(errs []FieldError) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return Validation("validation_failed", "%d invalid field(s)", len(errs)).With("errors", errs)
+}
@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"financial_assistance/internal/apperror"
+	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrUnauthenticated    = apperror.Unauthenticated("unauthenticated", "authentication required")
+	ErrInvalidCredentials = apperror.Unauthenticated("invalid_credentials", "invalid username or password")
+	ErrInvalidToken       = apperror.Unauthenticated("invalid_token", "access token is invalid or expired")
+	ErrInvalidAPIKey      = apperror.Unauthenticated("invalid_api_key", "API key is invalid or revoked")
+
+	ErrInvalidUsername = apperror.Validation("invalid_username", "invalid username")
+	ErrInvalidPassword = apperror.Validation("invalid_password", "invalid password")
+	ErrInvalidRole     = apperror.Validation("invalid_role", "role must be one of admin, caseworker, auditor")
+)
+
+// Authenticator logs staff users in, resolves the credentials of a request
+// to a Principal and manages accounts and API keys.
+type Authenticator struct {
+	users  repository.UserRepository
+	keys   repository.APIKeyRepository
+	tokens *TokenIssuer
+}
+
+func NewAuthenticator(users repository.UserRepository, keys repository.APIKeyRepository, tokens *TokenIssuer) *Authenticator {
+	return &Authenticator{users: users, keys: keys, tokens: tokens}
+}
+
+// Login checks a username and password and returns an access token with its
+// expiry.
+func (a *Authenticator) Login(ctx context.Context, username, password string) (string, time.Time, error) {
+	user, err := a.users.GetUserByUsername(ctx, username)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return "", time.Time{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if !checkPassword(user.PasswordHash, password) || user.Disabled {
+		return "", time.Time{}, ErrInvalidCredentials
+	}
+
+	return a.tokens.Issue(user.ID, time.Now())
+}
+
+// AuthenticateToken resolves an access token. Tokens of users that were
+// disabled or removed since login are rejected.
+func (a *Authenticator) AuthenticateToken(ctx context.Context, token string) (*Principal, error) {
+	id, err := a.tokens.Verify(token)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := a.users.GetUser(ctx, id)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled {
+		return nil, ErrInvalidToken
+	}
+
+	return &Principal{Kind: PrincipalUser, ID: user.ID, Name: user.Username, Role: user.Role}, nil
+}
+
+func (a *Authenticator) AuthenticateAPIKey(ctx context.Context, key string) (*Principal, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	stored, err := a.keys.GetAPIKeyByHash(ctx, hashAPIKey(key))
+	if errors.Is(err, repository.ErrAPIKeyNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	if stored.RevokedAt != nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	return &Principal{Kind: PrincipalAPIKey, ID: stored.ID, Name: stored.Name, Role: stored.Role}, nil
+}
+
+func (a *Authenticator) CreateUser(ctx context.Context, username, password string, role models.Role) (*models.User, error) {
+	if err := validateUsername(username); err != nil {
+		return nil, err
+	}
+	if !role.Valid() {
+		return nil, ErrInvalidRole
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		ID:           uuid.Must(uuid.NewV7()),
+		Username:     username,
+		PasswordHash: hash,
+		Role:         role,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := a.users.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// EnsureUser creates the user as an administrator unless one with the
+// username exists, and reports whether it did.
+func (a *Authenticator) EnsureUser(ctx context.Context, username, password string) (bool, error) {
+	_, err := a.users.GetUserByUsername(ctx, username)
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, repository.ErrUserNotFound) {
+		return false, err
+	}
+
+	_, err = a.CreateUser(ctx, username, password, models.RoleAdmin)
+	if errors.Is(err, repository.ErrUsernameTaken) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (a *Authenticator) SetPassword(ctx context.Context, username, password string) error {
+	user, err := a.users.GetUserByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if user.PasswordHash, err = hashPassword(password); err != nil {
+		return err
+	}
+	return a.users.UpdateUser(ctx, user)
+}
+
+// SetRole changes the role of a user. It applies to tokens issued before,
+// since the role is looked up on every request.
+func (a *Authenticator) SetRole(ctx context.Context, username string, role models.Role) error {
+	if !role.Valid() {
+		return ErrInvalidRole
+	}
+	user, err := a.users.GetUserByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	user.Role = role
+	return a.users.UpdateUser(ctx, user)
+}
+
+// SetDisabled blocks or unblocks a user. A disabled user can neither log in
+// nor use tokens issued before.
+func (a *Authenticator) SetDisabled(ctx context.Context, username string, disabled bool) error {
+	user, err := a.users.GetUserByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	user.Disabled = disabled
+	return a.users.UpdateUser(ctx, user)
+}
+
+// CreateAPIKey returns the new key in plain text along with its stored
+// record. The plain key cannot be recovered later.
+func (a *Authenticator) CreateAPIKey(ctx context.Context, name string, role models.Role) (string, *models.APIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, apperror.Validation("invalid_api_key_name", "API key name is required")
+	}
+	if !role.Valid() {
+		return "", nil, ErrInvalidRole
+	}
+
+	plain, prefix, err := generateAPIKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	key := &models.APIKey{
+		ID:        uuid.Must(uuid.NewV7()),
+		Name:      name,
+		Prefix:    prefix,
+		Hash:      hashAPIKey(plain),
+		Role:      role,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := a.keys.CreateAPIKey(ctx, key); err != nil {
+		return "", nil, err
+	}
+	return plain, key, nil
+}
+
+func (a *Authenticator) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	return a.keys.GetAllAPIKeys(ctx)
+}
+
+func (a *Authenticator) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	return a.keys.RevokeAPIKey(ctx, id, time.Now().UTC())
+}
+
+func validateUsername(username string) error {
+	if username == "" || len(username) > 255 {
+		return fmt.Errorf("%w: must be between 1 and 255 characters", ErrInvalidUsername)
+	}
+	for _, r := range username {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return fmt.Errorf("%w: must not contain spaces", ErrInvalidUsername)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository/memory"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	testSecret   = "0123456789abcdef0123456789abcdef"
+	testPassword = "correct horse battery"
+)
+
+func newTestAuthenticator() *Authenticator {
+	store := memory.NewStore()
+	tokens := NewTokenIssuer([]byte(testSecret), "financial-assistance", time.Hour)
+	return NewAuthenticator(memory.NewUserRepo(store), memory.NewAPIKeyRepo(store), tokens)
+}
+
+func TestLogin(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAuthenticator()
+	user, err := a.CreateUser(ctx, "mary", testPassword, models.RoleCaseworker)
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	token, _, err := a.Login(ctx, "mary", testPassword)
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	principal, err := a.AuthenticateToken(ctx, token)
+	if err != nil {
+		t.Fatalf("AuthenticateToken: %v", err)
+	}
+	if principal.Kind != PrincipalUser || principal.ID != user.ID || principal.Role != models.RoleCaseworker {
+		t.Errorf("principal = %+v, want caseworker %s", principal, user.ID)
+	}
+
+	for _, tt := range []struct{ name, username, password string }{
+		{"wrong password", "mary", "incorrect horse battery"},
+		{"unknown user", "nobody", testPassword},
+	} {
+		if _, _, err := a.Login(ctx, tt.username, tt.password); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("%s: Login = %v, want ErrInvalidCredentials", tt.name, err)
+		}
+	}
+}
+
+func TestDisabledUser(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAuthenticator()
+	if _, err := a.CreateUser(ctx, "mary", testPassword, models.RoleCaseworker); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	token, _, err := a.Login(ctx, "mary", testPassword)
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	if err := a.SetDisabled(ctx, "mary", true); err != nil {
+		t.Fatalf("SetDisabled: %v", err)
+	}
+	if _, _, err := a.Login(ctx, "mary", testPassword); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Login while disabled = %v, want ErrInvalidCredentials", err)
+	}
+	if _, err := a.AuthenticateToken(ctx, token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("token issued before disabling = %v, want ErrInvalidToken", err)
+	}
+
+	if err := a.SetDisabled(ctx, "mary", false); err != nil {
+		t.Fatalf("SetDisabled: %v", err)
+	}
+	if _, err := a.AuthenticateToken(ctx, token); err != nil {
+		t.Errorf("token after enabling again = %v", err)
+	}
+}
+
+func TestVerifyToken(t *testing.T) {
+	issuer := NewTokenIssuer([]byte(testSecret), "financial-assistance", time.Hour)
+	userID := uuid.New()
+	issue := func(t *testing.T, issuer *TokenIssuer, now time.Time) string {
+		t.Helper()
+		token, _, err := issuer.Issue(userID, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	token := issue(t, issuer, time.Now())
+	if id, err := issuer.Verify(token); err != nil || id != userID {
+		t.Fatalf("Verify = %s, %v, want %s", id, err, userID)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"expired", issue(t, issuer, time.Now().Add(-2*time.Hour))},
+		{"not yet valid", issue(t, issuer, time.Now().Add(time.Hour))},
+		{"wrong issuer", issue(t, NewTokenIssuer([]byte(testSecret), "someone-else", time.Hour), time.Now())},
+		{"wrong secret", issue(t, NewTokenIssuer([]byte(strings.Repeat("x", 32)), "financial-assistance", time.Hour), time.Now())},
+		{"tampered", token[:len(token)-2] + "xx"},
+		{"garbage", "not.a.token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := issuer.Verify(tt.token); err == nil {
+				t.Error("Verify succeeded")
+			}
+		})
+	}
+}
+
+func TestAuthenticateTokenRejectsInvalidTokens(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAuthenticator()
+	user, err := a.CreateUser(ctx, "mary", testPassword, models.RoleCaseworker)
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	expired, _, _ := a.tokens.Issue(user.ID, time.Now().Add(-2*time.Hour))
+	foreign, _, _ := NewTokenIssuer([]byte(testSecret), "someone-else", time.Hour).Issue(user.ID, time.Now())
+	unknown, _, _ := a.tokens.Issue(uuid.New(), time.Now())
+
+	for name, token := range map[string]string{"expired": expired, "wrong issuer": foreign, "unknown user": unknown} {
+		if _, err := a.AuthenticateToken(ctx, token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: AuthenticateToken = %v, want ErrInvalidToken", name, err)
+		}
+	}
+}
+
+func TestAPIKeys(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAuthenticator()
+
+	plain, key, err := a.CreateAPIKey(ctx, "  intake portal ", models.RoleAuditor)
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+	if !strings.HasPrefix(plain, apiKeyPrefix) || !strings.HasPrefix(plain, key.Prefix) {
+		t.Errorf("key %q does not start with %q and its prefix %q", plain, apiKeyPrefix, key.Prefix)
+	}
+	if key.Hash != hashAPIKey(plain) || strings.Contains(key.Hash, plain) {
+		t.Errorf("stored hash %q is not the hash of the key", key.Hash)
+	}
+	if key.Name != "intake portal" {
+		t.Errorf("name = %q, want it trimmed", key.Name)
+	}
+
+	principal, err := a.AuthenticateAPIKey(ctx, plain)
+	if err != nil {
+		t.Fatalf("AuthenticateAPIKey: %v", err)
+	}
+	if principal.Kind != PrincipalAPIKey || principal.ID != key.ID || principal.Role != models.RoleAuditor {
+		t.Errorf("principal = %+v, want auditor key %s", principal, key.ID)
+	}
+
+	other, _, err := generateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, candidate := range map[string]string{
+		"unknown":        other,
+		"without prefix": strings.TrimPrefix(plain, apiKeyPrefix),
+		"truncated":      plain[:len(plain)-1],
+	} {
+		if _, err := a.AuthenticateAPIKey(ctx, candidate); !errors.Is(err, ErrInvalidAPIKey) {
+			t.Errorf("%s key: AuthenticateAPIKey = %v, want ErrInvalidAPIKey", name, err)
+		}
+	}
+
+	if err := a.RevokeAPIKey(ctx, key.ID); err != nil {
+		t.Fatalf("RevokeAPIKey: %v", err)
+	}
+	if _, err := a.AuthenticateAPIKey(ctx, plain); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("revoked key: AuthenticateAPIKey = %v, want ErrInvalidAPIKey", err)
+	}
+}
+
+func TestHashAPIKey(t *testing.T) {
+	// echo -n fas_example | sha256sum
+	const want = "7652839d8565dbf10a262b797d06b67579456a25fe685f88cac80f5254730cba"
+	if got := hashAPIKey("fas_example"); got != want {
+		t.Errorf("hashAPIKey = %q, want %q", got, want)
+	}
+}
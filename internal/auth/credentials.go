@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password length limits. bcrypt ignores everything past 72 bytes.
+const (
+	minPasswordLength = 12
+	maxPasswordLength = 72
+)
+
+func hashPassword(password string) (string, error) {
+	if len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return "", fmt.Errorf("%w: must be between %d and %d bytes", ErrInvalidPassword, minPasswordLength, maxPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+func checkPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// dummyPasswordHash is compared against when a username does not exist, so
+// that failed logins take the same time whether or not the user exists.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
+
+// API keys are apiKeyPrefix followed by 32 random bytes in base64url. They
+// carry enough entropy that a plain SHA-256 is a safe way to store them and
+// lets keys be looked up by their hash.
+const (
+	apiKeyPrefix     = "fas_"
+	apiKeyShownChars = 8
+)
+
+func generateAPIKey() (key, prefix string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	return key, key[:len(apiKeyPrefix)+apiKeyShownChars], nil
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// TokenIssuer signs and verifies the HS256 access tokens handed out at
+// login. The subject of a token is the user ID.
+type TokenIssuer struct {
+	secret []byte
+	issuer string
+	ttl    time.Duration
+}
+
+func NewTokenIssuer(secret []byte, issuer string, ttl time.Duration) *TokenIssuer {
+	return &TokenIssuer{secret: secret, issuer: issuer, ttl: ttl}
+}
+
+func (t *TokenIssuer) Issue(userID uuid.UUID, now time.Time) (string, time.Time, error) {
+	expiresAt := now.Add(t.ttl)
+	claims := jwt.RegisteredClaims{
+		Issuer:    t.issuer,
+		Subject:   userID.String(),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		ID:        uuid.NewString(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(t.secret)
+	return token, expiresAt, err
+}
+
+// Verify checks the token's signature, issuer and lifetime and returns the
+// user ID it was issued to.
+func (t *TokenIssuer) Verify(token string) (uuid.UUID, error) {
+	var claims jwt.RegisteredClaims
+	_, err := jwt.ParseWithClaims(token, &claims,
+		func(*jwt.Token) (any, error) { return t.secret, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(t.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	id, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return uuid.Nil, errors.New("token subject is not a user ID")
+	}
+	return id, nil
+}
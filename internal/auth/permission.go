@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"financial_assistance/internal/apperror"
+	"financial_assistance/internal/models"
+	"fmt"
+	"slices"
+)
+
+var ErrForbidden = apperror.Forbidden("forbidden", "not permitted for this role")
+
+// Permission is an action a principal may be granted through its role.
+type Permission string
+
+const (
+	ReadApplicants    Permission = "applicants:read"
+	WriteApplicants   Permission = "applicants:write"
+	ReadSchemes       Permission = "schemes:read"
+	WriteSchemes      Permission = "schemes:write"
+	ReadApplications  Permission = "applications:read"
+	WriteApplications Permission = "applications:write"
+)
+
+// RolePermissions is the permission matrix: administrators may do
+// everything, caseworkers manage applicants and applications but not the
+// schemes they apply to, and auditors only read.
+var RolePermissions = map[models.Role][]Permission{
+	models.RoleAdmin: {
+		ReadApplicants, WriteApplicants,
+		ReadSchemes, WriteSchemes,
+		ReadApplications, WriteApplications,
+	},
+	models.RoleCaseworker: {
+		ReadApplicants, WriteApplicants,
+		ReadSchemes,
+		ReadApplications, WriteApplications,
+	},
+	models.RoleAuditor: {
+		ReadApplicants,
+		ReadSchemes,
+		ReadApplications,
+	},
+}
+
+// Allowed reports whether role grants permission.
+func Allowed(role models.Role, permission Permission) bool {
+	return slices.Contains(RolePermissions[role], permission)
+}
+
+// Authorize checks that the principal of ctx holds permission. A context
+// without a principal is rejected, so that an unauthenticated route cannot
+// reach the service by mistake.
+func Authorize(ctx context.Context, permission Permission) error {
+	principal, ok := FromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+	if !Allowed(principal.Role, permission) {
+		return fmt.Errorf("%w: role %q lacks %s", ErrForbidden, principal.Role, permission)
+	}
+	return nil
+}
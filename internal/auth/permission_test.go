@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"financial_assistance/internal/models"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestAllowed(t *testing.T) {
+	// The full matrix, spelled out rather than derived from
+	// RolePermissions, so that a change to it has to be made twice.
+	want := map[Permission]map[models.Role]bool{
+		ReadApplicants:    {models.RoleAdmin: true, models.RoleCaseworker: true, models.RoleAuditor: true},
+		WriteApplicants:   {models.RoleAdmin: true, models.RoleCaseworker: true, models.RoleAuditor: false},
+		ReadSchemes:       {models.RoleAdmin: true, models.RoleCaseworker: true, models.RoleAuditor: true},
+		WriteSchemes:      {models.RoleAdmin: true, models.RoleCaseworker: false, models.RoleAuditor: false},
+		ReadApplications:  {models.RoleAdmin: true, models.RoleCaseworker: true, models.RoleAuditor: true},
+		WriteApplications: {models.RoleAdmin: true, models.RoleCaseworker: true, models.RoleAuditor: false},
+	}
+	for permission, roles := range want {
+		for _, role := range models.Roles {
+			if got := Allowed(role, permission); got != roles[role] {
+				t.Errorf("Allowed(%s, %s) = %v, want %v", role, permission, got, roles[role])
+			}
+		}
+		if Allowed("", permission) || Allowed("superuser", permission) {
+			t.Errorf("an unknown role is allowed %s", permission)
+		}
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	if err := Authorize(context.Background(), ReadSchemes); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("without a principal: Authorize = %v, want ErrUnauthenticated", err)
+	}
+
+	auditor := NewContext(context.Background(), &Principal{Kind: PrincipalAPIKey, ID: uuid.New(), Role: models.RoleAuditor})
+	if err := Authorize(auditor, ReadSchemes); err != nil {
+		t.Errorf("auditor reading schemes: Authorize = %v", err)
+	}
+	if err := Authorize(auditor, WriteSchemes); !errors.Is(err, ErrForbidden) {
+		t.Errorf("auditor writing schemes: Authorize = %v, want ErrForbidden", err)
+	}
+}
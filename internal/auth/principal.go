@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"context"
+	"financial_assistance/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type PrincipalKind string
+
+const (
+	PrincipalUser   PrincipalKind = "user"
+	PrincipalAPIKey PrincipalKind = "api_key"
+)
+
+// Principal is the authenticated caller of a request: a staff user or the
+// integration holding an API key.
+type Principal struct {
+	Kind PrincipalKind `json:"kind"`
+	ID   uuid.UUID     `json:"id"`
+	Name string        `json:"name"`
+	Role models.Role   `json:"role"`
+}
+
+// Subject identifies the principal in audit records, e.g. "user:jdoe". API
+// keys are identified by ID since their names need not be unique.
+func (p *Principal) Subject() string {
+	if p.Kind == PrincipalAPIKey {
+		return string(p.Kind) + ":" + p.ID.String()
+	}
+	return string(p.Kind) + ":" + p.Name
+}
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the principal stored by NewContext, if any.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(*Principal)
+	return p, ok
+}
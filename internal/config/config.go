@@ -0,0 +1,369 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"financial_assistance/pkg/database"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is assembled from, in increasing order of precedence, the defaults
+// below, the optional file named by CONFIG_FILE (YAML or JSON) and
+// environment variables.
+type Config struct {
+	Storage  string         `json:"storage" yaml:"storage"`
+	HTTP     HTTPConfig     `json:"http" yaml:"http"`
+	Database DatabaseConfig `json:"database" yaml:"database"`
+
+	Idempotency IdempotencyConfig `json:"idempotency" yaml:"idempotency"`
+	Auth        AuthConfig        `json:"auth" yaml:"auth"`
+	Log         LogConfig         `json:"log" yaml:"log"`
+	Encryption  EncryptionConfig  `json:"encryption" yaml:"encryption"`
+}
+
+type HTTPConfig struct {
+	Addr              string   `json:"addr" yaml:"addr"`
+	ReadTimeout       Duration `json:"read_timeout" yaml:"read_timeout"`
+	ReadHeaderTimeout Duration `json:"read_header_timeout" yaml:"read_header_timeout"`
+	WriteTimeout      Duration `json:"write_timeout" yaml:"write_timeout"`
+	IdleTimeout       Duration `json:"idle_timeout" yaml:"idle_timeout"`
+}
+
+type DatabaseConfig struct {
+	Host         string `json:"host" yaml:"host"`
+	Port         int    `json:"port" yaml:"port"`
+	User         string `json:"user" yaml:"user"`
+	Password     string `json:"password" yaml:"password"`
+	PasswordFile string `json:"password_file" yaml:"password_file"`
+	Name         string `json:"name" yaml:"name"`
+	SSLMode      string `json:"sslmode" yaml:"sslmode"`
+
+	MaxOpenConns    int      `json:"max_open_conns" yaml:"max_open_conns"`
+	MaxIdleConns    int      `json:"max_idle_conns" yaml:"max_idle_conns"`
+	ConnMaxLifetime Duration `json:"conn_max_lifetime" yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime Duration `json:"conn_max_idle_time" yaml:"conn_max_idle_time"`
+
+	// MigrateOnStart applies pending schema migrations before serving.
+	MigrateOnStart bool `json:"migrate_on_start" yaml:"migrate_on_start"`
+}
+
+type IdempotencyConfig struct {
+	// TTL is how long a stored Idempotency-Key response is replayed.
+	TTL Duration `json:"ttl" yaml:"ttl"`
+}
+
+type AuthConfig struct {
+	// JWTSecret signs the access tokens issued at login.
+	JWTSecret     string   `json:"jwt_secret" yaml:"jwt_secret"`
+	JWTSecretFile string   `json:"jwt_secret_file" yaml:"jwt_secret_file"`
+	Issuer        string   `json:"issuer" yaml:"issuer"`
+	TokenTTL      Duration `json:"token_ttl" yaml:"token_ttl"`
+
+	// BootstrapUser is created with BootstrapPassword at startup if it does
+	// not exist yet, so that a fresh deployment can be logged into.
+	BootstrapUser         string `json:"bootstrap_user" yaml:"bootstrap_user"`
+	BootstrapPassword     string `json:"bootstrap_password" yaml:"bootstrap_password"`
+	BootstrapPasswordFile string `json:"bootstrap_password_file" yaml:"bootstrap_password_file"`
+}
+
+type LogConfig struct {
+	// Level is debug, info, warn or error.
+	Level string `json:"level" yaml:"level"`
+	// Format is text or json.
+	Format string `json:"format" yaml:"format"`
+}
+
+// SlogLevel returns the parsed Level, or info if it is invalid.
+func (c LogConfig) SlogLevel() slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Level)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+type EncryptionConfig struct {
+	// KeyringFile holds the keys that seal personal data in PostgreSQL; see
+	// encryption.LoadKeyring.
+	KeyringFile string `json:"keyring_file" yaml:"keyring_file"`
+}
+
+// minJWTSecretLength is the key size HS256 needs to be at full strength.
+const minJWTSecretLength = 32
+
+func Default() Config {
+	return Config{
+		Storage: "postgres",
+		HTTP: HTTPConfig{
+			Addr:              ":8080",
+			ReadTimeout:       Duration(15 * time.Second),
+			ReadHeaderTimeout: Duration(5 * time.Second),
+			WriteTimeout:      Duration(30 * time.Second),
+			IdleTimeout:       Duration(2 * time.Minute),
+		},
+		Database: DatabaseConfig{
+			Host:            "localhost",
+			Port:            5433,
+			User:            "postgres",
+			Name:            "Tutorial1",
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: Duration(30 * time.Minute),
+			ConnMaxIdleTime: Duration(5 * time.Minute),
+		},
+		Idempotency: IdempotencyConfig{
+			TTL: Duration(24 * time.Hour),
+		},
+		Auth: AuthConfig{
+			Issuer:   "financial-assistance",
+			TokenTTL: Duration(time.Hour),
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "text",
+		},
+	}
+}
+
+// Settings selects the groups of settings to validate besides the storage,
+// database and log settings that every command uses.
+type Settings uint
+
+const (
+	// ServerSettings are the HTTP, idempotency and auth settings of the API
+	// server.
+	ServerSettings Settings = 1 << iota
+	// EncryptionSettings are the keyring that seals personal data in
+	// PostgreSQL.
+	EncryptionSettings
+
+	AllSettings = ServerSettings | EncryptionSettings
+)
+
+// Load builds the configuration from the defaults, the config file and the
+// process environment, reads secrets from files and validates the result
+// with the settings given.
+func Load(settings Settings) (*Config, error) {
+	return load(os.LookupEnv, settings)
+}
+
+func load(lookup func(string) (string, bool), settings Settings) (*Config, error) {
+	cfg := Default()
+
+	if path, ok := lookup("CONFIG_FILE"); ok && path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.applyEnv(lookup); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.resolveSecrets(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(settings); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, c)
+	case ".json":
+		err = json.Unmarshal(data, c)
+	default:
+		return fmt.Errorf("config file %s: unsupported format %q (expected .yaml, .yml or .json)", path, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	for _, s := range c.secrets() {
+		if *s.value != "" && *s.file != "" {
+			return fmt.Errorf("config file %s: set only one of %s and %s_file", path, s.key, s.key)
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	env := envReader{lookup: lookup}
+
+	env.string("STORAGE", &c.Storage)
+
+	env.string("HTTP_ADDR", &c.HTTP.Addr)
+	env.duration("HTTP_READ_TIMEOUT", &c.HTTP.ReadTimeout)
+	env.duration("HTTP_READ_HEADER_TIMEOUT", &c.HTTP.ReadHeaderTimeout)
+	env.duration("HTTP_WRITE_TIMEOUT", &c.HTTP.WriteTimeout)
+	env.duration("HTTP_IDLE_TIMEOUT", &c.HTTP.IdleTimeout)
+
+	env.string("DB_HOST", &c.Database.Host)
+	env.int("DB_PORT", &c.Database.Port)
+	env.string("DB_USER", &c.Database.User)
+	env.string("DB_NAME", &c.Database.Name)
+	env.string("DB_SSLMODE", &c.Database.SSLMode)
+	env.int("DB_MAX_OPEN_CONNS", &c.Database.MaxOpenConns)
+	env.int("DB_MAX_IDLE_CONNS", &c.Database.MaxIdleConns)
+	env.duration("DB_CONN_MAX_LIFETIME", &c.Database.ConnMaxLifetime)
+	env.duration("DB_CONN_MAX_IDLE_TIME", &c.Database.ConnMaxIdleTime)
+	env.bool("DB_MIGRATE_ON_START", &c.Database.MigrateOnStart)
+
+	env.duration("IDEMPOTENCY_TTL", &c.Idempotency.TTL)
+
+	env.string("AUTH_ISSUER", &c.Auth.Issuer)
+	env.duration("AUTH_TOKEN_TTL", &c.Auth.TokenTTL)
+	env.string("AUTH_BOOTSTRAP_USER", &c.Auth.BootstrapUser)
+
+	env.string("LOG_LEVEL", &c.Log.Level)
+	env.string("LOG_FORMAT", &c.Log.Format)
+
+	env.string("ENCRYPTION_KEYRING_FILE", &c.Encryption.KeyringFile)
+
+	for _, s := range c.secrets() {
+		env.secret(s.env, s.value, s.file)
+	}
+
+	return errors.Join(env.errs...)
+}
+
+// secret is a setting that may be given directly or read from a file.
+type secret struct {
+	key   string // config file key; the file variant adds "_file"
+	env   string // environment variable; the file variant adds "_FILE"
+	value *string
+	file  *string
+}
+
+func (c *Config) secrets() []secret {
+	return []secret{
+		{"database.password", "DB_PASSWORD", &c.Database.Password, &c.Database.PasswordFile},
+		{"auth.jwt_secret", "AUTH_JWT_SECRET", &c.Auth.JWTSecret, &c.Auth.JWTSecretFile},
+		{"auth.bootstrap_password", "AUTH_BOOTSTRAP_PASSWORD", &c.Auth.BootstrapPassword, &c.Auth.BootstrapPasswordFile},
+	}
+}
+
+func (c *Config) resolveSecrets() error {
+	for _, s := range c.secrets() {
+		if *s.file == "" {
+			continue
+		}
+		value, err := readSecretFile(*s.file)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", s.key, err)
+		}
+		*s.value = value
+	}
+	return nil
+}
+
+// readSecretFile returns the file's contents without the trailing newline
+// most editors and secret stores add.
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// Validate checks the settings every command uses and the groups given, so
+// that, for example, migrations can run without the auth secrets.
+func (c *Config) Validate(settings Settings) error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Storage == "postgres" || c.Storage == "memory",
+		"storage must be \"postgres\" or \"memory\", got %q", c.Storage)
+
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.Log.Level)) == nil,
+		"log.level must be debug, info, warn or error, got %q", c.Log.Level)
+	check(c.Log.Format == "text" || c.Log.Format == "json",
+		"log.format must be \"text\" or \"json\", got %q", c.Log.Format)
+
+	if c.Storage == "postgres" {
+		db := c.Database
+		check(db.Host != "", "database.host is required")
+		check(db.Port > 0 && db.Port <= 65535, "database.port must be between 1 and 65535, got %d", db.Port)
+		check(db.User != "", "database.user is required")
+		check(db.Name != "", "database.name is required")
+		check(validSSLModes[db.SSLMode], "database.sslmode %q is not supported", db.SSLMode)
+		check(db.MaxOpenConns >= 0, "database.max_open_conns must not be negative")
+		check(db.MaxIdleConns >= 0, "database.max_idle_conns must not be negative")
+		check(db.MaxOpenConns == 0 || db.MaxIdleConns <= db.MaxOpenConns,
+			"database.max_idle_conns (%d) must not exceed database.max_open_conns (%d)", db.MaxIdleConns, db.MaxOpenConns)
+		check(db.ConnMaxLifetime >= 0, "database.conn_max_lifetime must not be negative")
+		check(db.ConnMaxIdleTime >= 0, "database.conn_max_idle_time must not be negative")
+	}
+
+	if settings&ServerSettings != 0 {
+		check(c.HTTP.Addr != "", "http.addr is required")
+		check(c.HTTP.ReadTimeout >= 0, "http.read_timeout must not be negative")
+		check(c.HTTP.ReadHeaderTimeout >= 0, "http.read_header_timeout must not be negative")
+		check(c.HTTP.WriteTimeout >= 0, "http.write_timeout must not be negative")
+		check(c.HTTP.IdleTimeout >= 0, "http.idle_timeout must not be negative")
+
+		check(c.Idempotency.TTL > 0, "idempotency.ttl must be positive")
+
+		check(len(c.Auth.JWTSecret) >= minJWTSecretLength,
+			"auth.jwt_secret is required and must be at least %d bytes", minJWTSecretLength)
+		check(c.Auth.TokenTTL > 0, "auth.token_ttl must be positive")
+		check((c.Auth.BootstrapUser == "") == (c.Auth.BootstrapPassword == ""),
+			"auth.bootstrap_user and auth.bootstrap_password must be set together")
+	}
+
+	if settings&EncryptionSettings != 0 && c.Storage == "postgres" {
+		check(c.Encryption.KeyringFile != "", "encryption.keyring_file is required with postgres storage")
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+var validSSLModes = map[string]bool{
+	"disable":     true,
+	"require":     true,
+	"verify-ca":   true,
+	"verify-full": true,
+}
+
+func (c DatabaseConfig) ConnectionConfig() *database.Config {
+	return &database.Config{
+		Host:            c.Host,
+		Port:            c.Port,
+		User:            c.User,
+		Password:        c.Password,
+		DBName:          c.Name,
+		SSLMode:         c.SSLMode,
+		MaxOpenConns:    c.MaxOpenConns,
+		MaxIdleConns:    c.MaxIdleConns,
+		ConnMaxLifetime: time.Duration(c.ConnMaxLifetime),
+		ConnMaxIdleTime: time.Duration(c.ConnMaxIdleTime),
+	}
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// env returns a lookup function over the variables given.
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := vars[name]
+		return value, ok
+	}
+}
+
+func TestDefaultDatabase(t *testing.T) {
+	db := Default().Database
+	if db.Port != 5433 || db.Name != "Tutorial1" {
+		t.Errorf("default database = port %d name %q, want port 5433 name %q", db.Port, db.Name, "Tutorial1")
+	}
+}
+
+func TestLoadValidatesSettingsGiven(t *testing.T) {
+	// Neither a JWT secret nor a keyring is configured.
+	lookup := env(map[string]string{"DB_HOST": "db"})
+
+	tests := []struct {
+		name     string
+		settings Settings
+		missing  []string
+	}{
+		{"database only", 0, nil},
+		{"encryption", EncryptionSettings, []string{"encryption.keyring_file"}},
+		{"server", ServerSettings, []string{"auth.jwt_secret"}},
+		{"all", AllSettings, []string{"auth.jwt_secret", "encryption.keyring_file"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := load(lookup, tt.settings)
+			if len(tt.missing) == 0 {
+				if err != nil {
+					t.Fatalf("load: %v", err)
+				}
+				if cfg.Database.Host != "db" {
+					t.Errorf("database.host = %q, want db", cfg.Database.Host)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("load succeeded, want %v reported missing", tt.missing)
+			}
+			for _, key := range tt.missing {
+				if !strings.Contains(err.Error(), key) {
+					t.Errorf("error %q does not mention %s", err, key)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadReportsInvalidDatabase(t *testing.T) {
+	_, err := load(env(map[string]string{"DB_PORT": "70000"}), 0)
+	if err == nil || !strings.Contains(err.Error(), "database.port") {
+		t.Errorf("load = %v, want an invalid database.port", err)
+	}
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// envReader copies set environment variables into config fields and collects
+// parse errors so they can be reported together.
+type envReader struct {
+	lookup func(string) (string, bool)
+	errs   []error
+}
+
+func (e *envReader) string(name string, dst *string) {
+	if value, ok := e.lookup(name); ok {
+		*dst = value
+	}
+}
+
+func (e *envReader) int(name string, dst *int) {
+	value, ok := e.lookup(name)
+	if !ok {
+		return
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s: %q is not an integer", name, value))
+		return
+	}
+	*dst = n
+}
+
+func (e *envReader) duration(name string, dst *Duration) {
+	value, ok := e.lookup(name)
+	if !ok {
+		return
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s: %q is not a duration (e.g. 30s, 5m)", name, value))
+		return
+	}
+	*dst = Duration(d)
+}
+
+func (e *envReader) bool(name string, dst *bool) {
+	value, ok := e.lookup(name)
+	if !ok {
+		return
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s: %q is not a boolean", name, value))
+		return
+	}
+	*dst = b
+}
+
+// secret reads a secret given either in name or in the file named by
+// name_FILE. Setting one replaces both values from earlier sources.
+func (e *envReader) secret(name string, value, file *string) {
+	_, hasValue := e.lookup(name)
+	_, hasFile := e.lookup(name + "_FILE")
+	switch {
+	case hasValue && hasFile:
+		e.errs = append(e.errs, fmt.Errorf("set only one of %s and %s_FILE", name, name))
+	case hasValue:
+		*file = ""
+		e.string(name, value)
+	case hasFile:
+		*value = ""
+		e.string(name+"_FILE", file)
+	}
+}
@@ -0,0 +1,195 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testKey returns a valid key made of b repeated.
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, keySize))
+}
+
+func mustKeyring(t *testing.T, active string, keys map[string]string) *Keyring {
+	t.Helper()
+	k, err := newKeyring(keyringFile{Active: active, Keys: keys, IndexKey: testKey(9)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestSealOpen(t *testing.T) {
+	k := mustKeyring(t, "k1", map[string]string{"k1": testKey(1)})
+	aad := []byte("applicants.name/1")
+
+	for _, plaintext := range []string{"Mary Tan", "", strings.Repeat("x", 1000)} {
+		sealed, err := k.Seal([]byte(plaintext), aad)
+		if err != nil {
+			t.Fatalf("Seal: %v", err)
+		}
+		if plaintext != "" && bytes.Contains(sealed, []byte(plaintext)) {
+			t.Errorf("sealed value contains the plaintext")
+		}
+		opened, err := k.Open(sealed, aad)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		if string(opened) != plaintext {
+			t.Errorf("Open = %q, want %q", opened, plaintext)
+		}
+	}
+
+	a, _ := k.Seal([]byte("Mary Tan"), aad)
+	b, _ := k.Seal([]byte("Mary Tan"), aad)
+	if bytes.Equal(a, b) {
+		t.Error("sealing the same value twice gave the same bytes")
+	}
+}
+
+func TestOpenRejectsValueMovedOrAltered(t *testing.T) {
+	k := mustKeyring(t, "k1", map[string]string{"k1": testKey(1)})
+	sealed, err := k.Seal([]byte("Mary Tan"), []byte("applicants.name/1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := bytes.Clone(sealed)
+	tampered[len(tampered)-1] ^= 1
+
+	tests := []struct {
+		name   string
+		sealed []byte
+		aad    string
+	}{
+		{"another row", sealed, "applicants.name/2"},
+		{"another column", sealed, "applicants.national_id/1"},
+		{"another table", sealed, "household_members.name/1"},
+		{"no additional data", sealed, ""},
+		{"altered ciphertext", tampered, "applicants.name/1"},
+		{"truncated", sealed[:len(sealed)-tagSize], "applicants.name/1"},
+		{"not sealed", []byte("Mary Tan"), "applicants.name/1"},
+		{"empty", nil, "applicants.name/1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if opened, err := k.Open(tt.sealed, []byte(tt.aad)); err == nil {
+				t.Errorf("Open = %q, want an error", opened)
+			}
+		})
+	}
+}
+
+func TestRotation(t *testing.T) {
+	aad := []byte("applicants.name/1")
+	old := mustKeyring(t, "k1", map[string]string{"k1": testKey(1)})
+	sealedOld, err := old.Seal([]byte("Mary Tan"), aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// k2 is added and made active; values sealed with k1 still open.
+	rotated := mustKeyring(t, "k2", map[string]string{"k1": testKey(1), "k2": testKey(2)})
+	if opened, err := rotated.Open(sealedOld, aad); err != nil || string(opened) != "Mary Tan" {
+		t.Fatalf("opening a value sealed before the rotation = %q, %v", opened, err)
+	}
+	sealedNew, err := rotated.Seal([]byte("Mary Tan"), aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := old.Open(sealedNew, aad); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("opening a value sealed with k2 without it = %v, want ErrUnknownKey", err)
+	}
+
+	// Once k1 is removed, values still sealed with it cannot be opened.
+	retired := mustKeyring(t, "k2", map[string]string{"k2": testKey(2)})
+	if _, err := retired.Open(sealedOld, aad); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("opening a value sealed with a removed key = %v, want ErrUnknownKey", err)
+	}
+	if opened, err := retired.Open(sealedNew, aad); err != nil || string(opened) != "Mary Tan" {
+		t.Errorf("opening a value sealed after the rotation = %q, %v", opened, err)
+	}
+
+	// A key replaced under the same ID does not open the old values.
+	replaced := mustKeyring(t, "k1", map[string]string{"k1": testKey(3)})
+	if _, err := replaced.Open(sealedOld, aad); err == nil {
+		t.Error("a different key with the same ID opened the value")
+	}
+}
+
+func TestNewKeyringValidates(t *testing.T) {
+	tests := []struct {
+		name string
+		file keyringFile
+		want string
+	}{
+		{"active missing", keyringFile{Active: "k2", Keys: map[string]string{"k1": testKey(1)}, IndexKey: testKey(9)}, `active key "k2"`},
+		{"not base64", keyringFile{Active: "k1", Keys: map[string]string{"k1": "not base64!"}, IndexKey: testKey(9)}, "must be base64"},
+		{"short key", keyringFile{Active: "k1", Keys: map[string]string{"k1": base64.StdEncoding.EncodeToString([]byte("short"))}, IndexKey: testKey(9)}, "must be 32 bytes"},
+		{"no index key", keyringFile{Active: "k1", Keys: map[string]string{"k1": testKey(1)}}, "index_key"},
+		{"long key ID", keyringFile{Active: "k1", Keys: map[string]string{"k1": testKey(1), strings.Repeat("k", 256): testKey(2)}, IndexKey: testKey(9)}, "key ID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newKeyring(tt.file)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("newKeyring = %v, want an error mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	data := `{"active": "2025-01", "keys": {"2024-06": "` + testKey(1) + `", "2025-01": "` + testKey(2) + `"}, "index_key": "` + testKey(9) + `"}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	k, err := LoadKeyring(path)
+	if err != nil {
+		t.Fatalf("LoadKeyring: %v", err)
+	}
+	if k.ActiveKeyID() != "2025-01" || len(k.keys) != 2 {
+		t.Errorf("keyring has %d keys with %q active, want 2 with 2025-01", len(k.keys), k.ActiveKeyID())
+	}
+
+	if _, err := LoadKeyring(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadKeyring of a missing file succeeded")
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	k := mustKeyring(t, "k1", map[string]string{"k1": testKey(1)})
+	index := k.BlindIndex("name_dob", "mary tan", "1985-03-14")
+
+	if !bytes.Equal(index, k.BlindIndex("name_dob", "mary tan", "1985-03-14")) {
+		t.Error("equal values hashed differently")
+	}
+	// Blind indexes do not depend on the encryption keys, so they survive a
+	// rotation.
+	rotated := mustKeyring(t, "k2", map[string]string{"k2": testKey(2)})
+	if !bytes.Equal(index, rotated.BlindIndex("name_dob", "mary tan", "1985-03-14")) {
+		t.Error("rotating the encryption keys changed the blind index")
+	}
+
+	other, err := newKeyring(keyringFile{Active: "k1", Keys: map[string]string{"k1": testKey(1)}, IndexKey: testKey(8)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, got := range map[string][]byte{
+		"another value":     k.BlindIndex("name_dob", "mary tan", "1985-03-15"),
+		"another index":     k.BlindIndex("national_id", "mary tan", "1985-03-14"),
+		"values split":      k.BlindIndex("name_dob", "mary tan1", "985-03-14"),
+		"another index key": other.BlindIndex("name_dob", "mary tan", "1985-03-14"),
+	} {
+		if bytes.Equal(index, got) {
+			t.Errorf("%s hashed like the original", name)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// Sealed values are laid out as
+//
+//	version (1) | key ID length (1) | key ID | wrapped data key | nonce | ciphertext
+//
+// Every value gets its own random data key, which is encrypted ("wrapped")
+// with a key from the keyring. Both layers use AES-256-GCM.
+const (
+	formatVersion  = 1
+	maxKeyIDLength = 255
+	nonceSize      = 12
+	tagSize        = 16
+
+	wrappedKeySize = nonceSize + keySize + tagSize
+)
+
+var ErrUnknownKey = errors.New("sealed with a key that is not in the keyring")
+
+// Seal encrypts plaintext with a new data key wrapped by the active key.
+// additionalData is authenticated but not stored; it binds the value to its
+// place, such as a column and row ID, so that sealed values cannot be moved
+// between records.
+func (k *Keyring) Seal(plaintext, additionalData []byte) ([]byte, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+
+	id := k.active
+	out := make([]byte, 0, 2+len(id)+wrappedKeySize+nonceSize+len(plaintext)+tagSize)
+	out = append(out, formatVersion, byte(len(id)))
+	out = append(out, id...)
+
+	out, err := gcmSeal(out, k.keys[id], dataKey, []byte(id))
+	if err != nil {
+		return nil, err
+	}
+	return gcmSeal(out, dataKey, plaintext, additionalData)
+}
+
+// Open decrypts a value produced by Seal with the same additional data.
+func (k *Keyring) Open(sealed, additionalData []byte) ([]byte, error) {
+	id, rest, err := splitKeyID(sealed)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+	if len(rest) < wrappedKeySize {
+		return nil, errors.New("sealed value is truncated")
+	}
+
+	dataKey, err := gcmOpen(key, rest[:wrappedKeySize], []byte(id))
+	if err != nil {
+		return nil, fmt.Errorf("unwrapping data key: %w", err)
+	}
+	plaintext, err := gcmOpen(dataKey, rest[wrappedKeySize:], additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting value: %w", err)
+	}
+	return plaintext, nil
+}
+
+func splitKeyID(sealed []byte) (string, []byte, error) {
+	if len(sealed) < 2 || sealed[0] != formatVersion {
+		return "", nil, errors.New("not a sealed value")
+	}
+	n := int(sealed[1])
+	if len(sealed) < 2+n {
+		return "", nil, errors.New("sealed value is truncated")
+	}
+	return string(sealed[2 : 2+n]), sealed[2+n:], nil
+}
+
+// gcmSeal appends nonce and ciphertext of plaintext to dst.
+func gcmSeal(dst, key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	dst = append(dst, nonce...)
+	return aead.Seal(dst, nonce, plaintext, additionalData), nil
+}
+
+func gcmOpen(key, sealed, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < nonceSize+tagSize {
+		return nil, errors.New("ciphertext is truncated")
+	}
+	return aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], additionalData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,22 @@
+package encryption
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// BlindIndex returns a keyed hash of the given values, which can be stored
+// next to their sealed form and compared for equality without revealing
+// them. Callers normalize the values first so that equal values hash alike.
+// name distinguishes the indexes, so equal inputs to different indexes do
+// not collide.
+func (k *Keyring) BlindIndex(name string, values ...string) []byte {
+	mac := hmac.New(sha256.New, k.indexKey)
+	for _, v := range append([]string{name}, values...) {
+		// Length prefixes keep ("ab", "c") apart from ("a", "bc").
+		mac.Write(binary.BigEndian.AppendUint32(nil, uint32(len(v))))
+		mac.Write([]byte(v))
+	}
+	return mac.Sum(nil)
+}
@@ -0,0 +1,103 @@
+// Package encryption seals individual fields with envelope encryption and
+// computes blind indexes, so that personal data can be stored encrypted and
+// still be looked up by exact value.
+package encryption
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const keySize = 32
+
+// Keyring holds the key-encryption keys, by ID, and the key used for blind
+// indexes. New data is sealed with the active key; the others are kept so
+// that data sealed before a rotation can still be opened until it has been
+// re-encrypted.
+type Keyring struct {
+	active   string
+	keys     map[string][]byte
+	indexKey []byte
+}
+
+// keyringFile is the layout of the keyring file. Keys are 32 random bytes in
+// standard base64, e.g. from "openssl rand -base64 32".
+type keyringFile struct {
+	Active   string            `json:"active"`
+	Keys     map[string]string `json:"keys"`
+	IndexKey string            `json:"index_key"`
+}
+
+// LoadKeyring reads a keyring file:
+//
+//	{
+//	    "active": "2025-01",
+//	    "keys": {"2024-06": "…", "2025-01": "…"},
+//	    "index_key": "…"
+//	}
+func LoadKeyring(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file keyringFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing keyring %s: %w", path, err)
+	}
+	keyring, err := newKeyring(file)
+	if err != nil {
+		return nil, fmt.Errorf("keyring %s: %w", path, err)
+	}
+	return keyring, nil
+}
+
+func newKeyring(file keyringFile) (*Keyring, error) {
+	k := &Keyring{active: file.Active, keys: make(map[string][]byte, len(file.Keys))}
+
+	var errs []error
+	for id, encoded := range file.Keys {
+		if id == "" || len(id) > maxKeyIDLength {
+			errs = append(errs, fmt.Errorf("key ID %q must be between 1 and %d characters", id, maxKeyIDLength))
+			continue
+		}
+		key, err := decodeKey(encoded)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("key %q: %w", id, err))
+			continue
+		}
+		k.keys[id] = key
+	}
+	if _, ok := file.Keys[file.Active]; !ok {
+		errs = append(errs, fmt.Errorf("active key %q is not in keys", file.Active))
+	}
+
+	var err error
+	if k.indexKey, err = decodeKey(file.IndexKey); err != nil {
+		errs = append(errs, fmt.Errorf("index_key: %w", err))
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return k, nil
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("must be base64")
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("must be %d bytes, got %d", keySize, len(key))
+	}
+	return key, nil
+}
+
+// ActiveKeyID is the ID of the key new data is sealed with.
+func (k *Keyring) ActiveKeyID() string {
+	return k.active
+}
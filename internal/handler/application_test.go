@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"financial_assistance/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newApplication creates an applicant and a scheme without criteria and
+// returns a draft application of the one for the other.
+func newApplication(api *testAPI) models.Application {
+	api.t.Helper()
+	var applicant models.Applicant
+	api.create("/api/applicants", applicantBody, &applicant)
+	var scheme models.Scheme
+	api.create("/api/schemes", `{"name": "Universal Grant"}`, &scheme)
+
+	var application models.Application
+	body := `{"applicant_id": "` + applicant.ID.String() + `", "scheme_id": "` + scheme.ID.String() + `"}`
+	api.create("/api/applications", body, &application)
+	return application
+}
+
+// transition posts a move to status with the If-Match header given.
+func (a *testAPI) transition(application models.Application, status, ifMatch string) *httptest.ResponseRecorder {
+	a.t.Helper()
+	path := "/api/applications/" + application.ID.String() + "/transitions"
+	return a.do("POST", path, `{"status": "`+status+`", "reason_code": "checked"}`, "If-Match", ifMatch)
+}
+
+func TestTransitionApplication(t *testing.T) {
+	api := newTestAPI(t)
+	application := newApplication(api)
+	if application.Status != models.StatusDraft {
+		t.Fatalf("new application is %s, want draft", application.Status)
+	}
+
+	for _, status := range []models.ApplicationStatus{
+		models.StatusSubmitted, models.StatusUnderReview, models.StatusApproved, models.StatusDisbursed,
+	} {
+		rec := api.transition(application, string(status), etag(application.Version))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("move to %s = %d %s, want 200", status, rec.Code, rec.Body)
+		}
+		var moved models.Application
+		decode(t, rec, &moved)
+		if moved.Status != status || moved.Version != application.Version+1 {
+			t.Errorf("moved to %s at version %d, want %s at %d", moved.Status, moved.Version, status, application.Version+1)
+		}
+		if got := rec.Header().Get("ETag"); got != etag(moved.Version) {
+			t.Errorf("ETag = %q, want %q", got, etag(moved.Version))
+		}
+		application = moved
+	}
+
+	var stored models.Application
+	decode(t, api.do("GET", "/api/applications/"+application.ID.String(), ""), &stored)
+	if stored.Status != models.StatusDisbursed || stored.Version != 5 {
+		t.Errorf("stored application is %s at version %d, want disbursed at 5", stored.Status, stored.Version)
+	}
+}
+
+func TestTransitionApplicationRefusesIllegalMoves(t *testing.T) {
+	api := newTestAPI(t)
+	draft := newApplication(api)
+
+	tests := []struct {
+		name   string
+		status string
+		code   string
+		want   int
+	}{
+		{"skipping ahead", "disbursed", "illegal_transition", http.StatusConflict},
+		{"to approved", "approved", "illegal_transition", http.StatusConflict},
+		{"to itself", "draft", "illegal_transition", http.StatusConflict},
+		{"unknown status", "pending", "invalid_status", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectProblem(t, api.transition(draft, tt.status, `"1"`), tt.want, tt.code)
+		})
+	}
+
+	withdrawn := api.transition(draft, "withdrawn", `"1"`)
+	if withdrawn.Code != http.StatusOK {
+		t.Fatalf("withdraw = %d %s", withdrawn.Code, withdrawn.Body)
+	}
+	expectProblem(t, api.transition(draft, "submitted", `"2"`), http.StatusConflict, "illegal_transition")
+
+	var stored models.Application
+	decode(t, api.do("GET", "/api/applications/"+draft.ID.String(), ""), &stored)
+	if stored.Status != models.StatusWithdrawn || stored.Version != 2 {
+		t.Errorf("stored application is %s at version %d, want withdrawn at 2", stored.Status, stored.Version)
+	}
+}
+
+func TestTransitionApplicationPreconditions(t *testing.T) {
+	api := newTestAPI(t)
+	application := newApplication(api)
+	path := "/api/applications/" + application.ID.String() + "/transitions"
+
+	expectProblem(t, api.do("POST", path, `{"status": "submitted"}`), http.StatusPreconditionRequired, "precondition_required")
+	for _, tag := range []string{`"2"`, `"0"`, `W/"1"`, `"one"`} {
+		expectProblem(t, api.transition(application, "submitted", tag), http.StatusPreconditionFailed, "version_mismatch")
+	}
+
+	var stored models.Application
+	decode(t, api.do("GET", "/api/applications/"+application.ID.String(), ""), &stored)
+	if stored.Status != models.StatusDraft || stored.Version != 1 {
+		t.Errorf("stored application is %s at version %d, want draft at 1", stored.Status, stored.Version)
+	}
+}
+
+func TestDuplicateAndRepeatApplications(t *testing.T) {
+	api := newTestAPI(t)
+	application := newApplication(api)
+	body := `{"applicant_id": "` + application.ApplicantID.String() + `", "scheme_id": "` + application.SchemeID.String() + `"}`
+
+	expectProblem(t, api.do("POST", "/api/applications", body), http.StatusConflict, "duplicate_application")
+
+	for _, status := range []string{"submitted", "under_review", "rejected"} {
+		rec := api.transition(application, status, etag(application.Version))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("move to %s = %d %s", status, rec.Code, rec.Body)
+		}
+		decode(t, rec, &application)
+	}
+	// The scheme does not allow reapplying after a rejection.
+	expectProblem(t, api.do("POST", "/api/applications", body), http.StatusConflict, "reapplication_not_allowed")
+}
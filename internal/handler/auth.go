@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"encoding/json"
+	"financial_assistance/internal/auth"
+	"financial_assistance/internal/logging"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type AuthHandler struct {
+	auth *auth.Authenticator
+}
+
+func NewAuthHandler(authenticator *auth.Authenticator) *AuthHandler {
+	return &AuthHandler{auth: authenticator}
+}
+
+type loginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type loginResponse struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req loginRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	token, expiresAt, err := h.auth.Login(r.Context(), req.Username, req.Password)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(loginResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresAt:   expiresAt.UTC(),
+	})
+}
+
+// Me returns the principal the request was authenticated as.
+func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.FromContext(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(principal)
+}
+
+// Authenticate rejects requests without valid credentials and stores the
+// principal of the others in the request context. Staff users send the
+// token from Login as "Authorization: Bearer <token>"; integrations send
+// their key in X-API-Key.
+func (h *AuthHandler) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey := r.Header.Get("X-API-Key")
+		authorization := r.Header.Get("Authorization")
+
+		var (
+			principal *auth.Principal
+			err       error
+		)
+		switch {
+		case apiKey != "" && authorization != "":
+			err = fmt.Errorf("%w: send either Authorization or X-API-Key, not both", auth.ErrUnauthenticated)
+		case apiKey != "":
+			principal, err = h.auth.AuthenticateAPIKey(r.Context(), apiKey)
+		case authorization != "":
+			scheme, token, _ := strings.Cut(authorization, " ")
+			if !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+				err = fmt.Errorf("%w: expected a Bearer token", auth.ErrUnauthenticated)
+				break
+			}
+			principal, err = h.auth.AuthenticateToken(r.Context(), strings.TrimSpace(token))
+		default:
+			err = auth.ErrUnauthenticated
+		}
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		ctx := auth.NewContext(r.Context(), principal)
+		ctx = logging.NewContext(ctx, logging.FromContext(ctx).With("principal", principal.Subject()))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
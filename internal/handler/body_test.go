@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"financial_assistance/internal/auth"
+	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository/memory"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestBodyLimit sends a body over maxBodySize to every endpoint that reads
+// one. The body is valid JSON, so that only its size can be refused.
+func TestBodyLimit(t *testing.T) {
+	api := newTestAPI(t)
+	application := newApplication(api)
+	var applicant models.Applicant
+	decode(t, api.do("GET", "/api/applicants/"+application.ApplicantID.String(), ""), &applicant)
+	applicantPath := "/api/applicants/" + applicant.ID.String()
+	schemePath := "/api/schemes/" + application.SchemeID.String()
+
+	huge := `{"name": "` + strings.Repeat("a", maxBodySize) + `"}`
+	tests := []struct {
+		method, path string
+	}{
+		{"POST", "/api/applicants"},
+		{"PUT", applicantPath},
+		{"PATCH", applicantPath},
+		{"POST", applicantPath + "/household"},
+		{"PUT", applicantPath + "/household/" + applicant.HouseholdMembers[0].ID.String()},
+		{"POST", "/api/schemes"},
+		{"PUT", schemePath},
+		{"PUT", schemePath + "/criteria"},
+		{"POST", schemePath + "/benefits"},
+		{"POST", "/api/applications"},
+		{"POST", "/api/applications/" + application.ID.String() + "/transitions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rec := api.do(tt.method, tt.path, huge, "If-Match", `"1"`)
+			expectProblem(t, rec, http.StatusRequestEntityTooLarge, "body_too_large")
+		})
+	}
+
+	t.Run("login", func(t *testing.T) {
+		store := memory.NewStore()
+		tokens := auth.NewTokenIssuer([]byte("0123456789abcdef0123456789abcdef"), "financial-assistance", time.Hour)
+		h := NewAuthHandler(auth.NewAuthenticator(memory.NewUserRepo(store), memory.NewAPIKeyRepo(store), tokens))
+
+		rec := httptest.NewRecorder()
+		h.Login(rec, httptest.NewRequest("POST", "/api/auth/login", strings.NewReader(huge)))
+		expectProblem(t, rec, http.StatusRequestEntityTooLarge, "body_too_large")
+	})
+
+	// Bodies within the limit are still accepted.
+	rec := api.do("PUT", schemePath+"/criteria", `{"max_household_income": 3000}`, "If-Match", `"1"`)
+	if rec.Code != http.StatusOK {
+		t.Errorf("PUT criteria = %d %s, want 200", rec.Code, rec.Body)
+	}
+}
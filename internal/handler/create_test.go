@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"financial_assistance/internal/models"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCreateAssignsIDs(t *testing.T) {
+	api := newTestAPI(t)
+	var applicant models.Applicant
+	applicantRec := api.create("/api/applicants", applicantBody, &applicant)
+	var scheme models.Scheme
+	schemeRec := api.create("/api/schemes", `{"name": "Universal Grant", "benefits": [{"name": "Cash", "amount": 500}]}`, &scheme)
+	var application models.Application
+	body := `{"applicant_id": "` + applicant.ID.String() + `", "scheme_id": "` + scheme.ID.String() + `"}`
+	applicationRec := api.create("/api/applications", body, &application)
+
+	if len(applicant.HouseholdMembers) != 1 || len(scheme.Benefits) != 1 {
+		t.Fatalf("created %d household members and %d benefits, want one of each", len(applicant.HouseholdMembers), len(scheme.Benefits))
+	}
+	ids := map[string]uuid.UUID{
+		"applicant":        applicant.ID,
+		"household member": applicant.HouseholdMembers[0].ID,
+		"scheme":           scheme.ID,
+		"benefit":          scheme.Benefits[0].ID,
+		"application":      application.ID,
+	}
+	for kind, id := range ids {
+		if id.Version() != 7 {
+			t.Errorf("%s ID %s is version %d, want 7", kind, id, id.Version())
+		}
+	}
+
+	tests := []struct {
+		kind, want string
+		location   string
+	}{
+		{"applicant", "/api/applicants/" + applicant.ID.String(), applicantRec.Header().Get("Location")},
+		{"scheme", "/api/schemes/" + scheme.ID.String(), schemeRec.Header().Get("Location")},
+		{"application", "/api/applications/" + application.ID.String(), applicationRec.Header().Get("Location")},
+	}
+	for _, tt := range tests {
+		if tt.location != tt.want {
+			t.Errorf("%s Location = %q, want %q", tt.kind, tt.location, tt.want)
+			continue
+		}
+		if rec := api.do("GET", tt.location, ""); rec.Code != http.StatusOK {
+			t.Errorf("GET %s = %d, want 200", tt.location, rec.Code)
+		}
+	}
+}
+
+func TestCreateWithClientID(t *testing.T) {
+	api := newTestAPI(t)
+	id := uuid.Must(uuid.NewV7())
+
+	var applicant models.Applicant
+	rec := api.create("/api/applicants", `{"id": "`+id.String()+`", `+applicantBody[1:], &applicant)
+	if applicant.ID != id || rec.Header().Get("Location") != "/api/applicants/"+id.String() {
+		t.Errorf("applicant created as %s at %q, want the ID sent", applicant.ID, rec.Header().Get("Location"))
+	}
+	expectProblem(t, api.do("POST", "/api/applicants", `{"id": "`+id.String()+`", `+applicantBody[1:]),
+		http.StatusConflict, "already_exists")
+
+	var scheme models.Scheme
+	api.create("/api/schemes", `{"id": "`+id.String()+`", "name": "Universal Grant"}`, &scheme)
+	if scheme.ID != id {
+		t.Errorf("scheme created as %s, want the ID sent", scheme.ID)
+	}
+	expectProblem(t, api.do("POST", "/api/schemes", `{"id": "`+id.String()+`", "name": "Other Grant"}`),
+		http.StatusConflict, "already_exists")
+
+	var application models.Application
+	body := `{"application_id": "` + id.String() + `", "applicant_id": "` + applicant.ID.String() + `", "scheme_id": "` + scheme.ID.String() + `"}`
+	api.create("/api/applications", body, &application)
+	if application.ID != id {
+		t.Errorf("application created as %s, want the ID sent", application.ID)
+	}
+	// Another pair, so that only the ID collides.
+	var other models.Scheme
+	api.create("/api/schemes", `{"name": "Other Grant"}`, &other)
+	body = `{"application_id": "` + id.String() + `", "applicant_id": "` + applicant.ID.String() + `", "scheme_id": "` + other.ID.String() + `"}`
+	expectProblem(t, api.do("POST", "/api/applications", body), http.StatusConflict, "already_exists")
+
+	// The refused creates left the stored records alone.
+	var stored models.Scheme
+	decode(t, api.do("GET", "/api/schemes/"+id.String(), ""), &stored)
+	if stored.Name != "Universal Grant" || stored.Version != 1 {
+		t.Errorf("stored scheme is %q at version %d, want Universal Grant at 1", stored.Name, stored.Version)
+	}
+	var storedApplication models.Application
+	decode(t, api.do("GET", "/api/applications/"+id.String(), ""), &storedApplication)
+	if storedApplication.SchemeID != scheme.ID {
+		t.Errorf("stored application is for scheme %s, want %s", storedApplication.SchemeID, scheme.ID)
+	}
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"financial_assistance/internal/apperror"
+	"financial_assistance/internal/logging"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+var kindStatus = map[apperror.Kind]int{
+	apperror.KindValidation:      http.StatusBadRequest,
+	apperror.KindUnauthenticated: http.StatusUnauthorized,
+	apperror.KindForbidden:       http.StatusForbidden,
+	apperror.KindNotFound:        http.StatusNotFound,
+	apperror.KindConflict:        http.StatusConflict,
+	apperror.KindUnprocessable:   http.StatusUnprocessableEntity,
+
+	apperror.KindPreconditionFailed:   http.StatusPreconditionFailed,
+	apperror.KindPreconditionRequired: http.StatusPreconditionRequired,
+	apperror.KindPayloadTooLarge:      http.StatusRequestEntityTooLarge,
+}
+
+// writeError renders err as an RFC 7807 problem. Errors that are not
+// apperror values are logged and reported as a generic internal error.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusInternalServerError
+	code := "internal_error"
+	detail := "internal server error"
+
+	var extensions map[string]any
+	if appErr := apperror.As(err); appErr != nil {
+		if s, ok := kindStatus[appErr.Kind]; ok {
+			status = s
+			code = appErr.Code
+			detail = err.Error()
+			extensions = appErr.Extensions
+		}
+	}
+	if status == http.StatusInternalServerError {
+		logging.FromContext(r.Context()).Error("request failed",
+			"method", r.Method, "path", r.URL.Path, "error", err)
+	}
+
+	body := make(map[string]any, len(extensions)+6)
+	for k, v := range extensions {
+		body[k] = v
+	}
+	body["type"] = "about:blank"
+	body["title"] = http.StatusText(status)
+	body["status"] = status
+	body["detail"] = detail
+	body["instance"] = r.URL.Path
+	body["code"] = code
+
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func invalidBody(err error) error {
+	return apperror.Validation("invalid_body", "invalid request body: %v", err)
+}
+
+// maxBodySize bounds the request bodies read into memory.
+const maxBodySize = 1 << 20
+
+// readBody reads the whole request body, refusing bodies larger than
+// maxBodySize.
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return nil, apperror.PayloadTooLarge("body_too_large", "request body must not exceed %d bytes", tooLarge.Limit)
+	}
+	if err != nil {
+		return nil, apperror.Validation("invalid_body", "error reading request body")
+	}
+	return body, nil
+}
+
+// decodeBody decodes the JSON request body into v, within the size limit of
+// readBody.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) error {
+	body, err := readBody(w, r)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return invalidBody(err)
+	}
+	return nil
+}
+
+// pathID parses the UUID in the named route variable; what names the record
+// in the error message.
+func pathID(r *http.Request, name, what string) (uuid.UUID, error) {
+	value := mux.Vars(r)[name]
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, apperror.Validation("invalid_id", "invalid %s ID %q", what, value)
+	}
+	return id, nil
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"financial_assistance/internal/apperror"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"validation", apperror.Validation("invalid_id", "bad id"), http.StatusBadRequest, "invalid_id"},
+		{"unauthenticated", apperror.Unauthenticated("invalid_token", "bad token"), http.StatusUnauthorized, "invalid_token"},
+		{"forbidden", apperror.Forbidden("forbidden", "not allowed"), http.StatusForbidden, "forbidden"},
+		{"not found", apperror.NotFound("applicant_not_found", "no such applicant"), http.StatusNotFound, "applicant_not_found"},
+		{"conflict", apperror.Conflict("duplicate", "already exists"), http.StatusConflict, "duplicate"},
+		{"unprocessable", apperror.Unprocessable("not_eligible", "not eligible"), http.StatusUnprocessableEntity, "not_eligible"},
+		{"precondition failed", apperror.PreconditionFailed("version_mismatch", "stale"), http.StatusPreconditionFailed, "version_mismatch"},
+		{"precondition required", apperror.PreconditionRequired("if_match_required", "If-Match required"), http.StatusPreconditionRequired, "if_match_required"},
+		{"payload too large", apperror.PayloadTooLarge("body_too_large", "too big"), http.StatusRequestEntityTooLarge, "body_too_large"},
+		{"wrapped", fmt.Errorf("loading: %w", apperror.NotFound("scheme_not_found", "no such scheme")), http.StatusNotFound, "scheme_not_found"},
+		{"internal kind", apperror.New(apperror.KindInternal, "boom", "secret detail"), http.StatusInternalServerError, "internal_error"},
+		{"plain error", errors.New("connection refused"), http.StatusInternalServerError, "internal_error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/things/1", nil)
+			rec := httptest.NewRecorder()
+			writeError(rec, req, tt.err)
+
+			p := expectProblem(t, rec, tt.status, tt.code)
+			if tt.status == http.StatusInternalServerError && p.Detail != "internal server error" {
+				t.Errorf("detail = %q, want the cause hidden", p.Detail)
+			}
+			if auth := rec.Header().Get("WWW-Authenticate"); (auth != "") != (tt.status == http.StatusUnauthorized) {
+				t.Errorf("WWW-Authenticate = %q with status %d", auth, tt.status)
+			}
+		})
+	}
+}
+
+func TestWriteErrorIncludesExtensions(t *testing.T) {
+	err := apperror.Fields([]apperror.FieldError{{Field: "sex", Code: "invalid_enum", Message: "must be male or female"}})
+
+	rec := httptest.NewRecorder()
+	writeError(rec, httptest.NewRequest("POST", "/api/applicants", nil), err)
+
+	p := expectProblem(t, rec, http.StatusBadRequest, "validation_failed")
+	if len(p.Errors) != 1 || p.Errors[0].Field != "sex" || p.Errors[0].Code != "invalid_enum" {
+		t.Errorf("errors = %+v, want the sex field error", p.Errors)
+	}
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"financial_assistance/internal/apperror"
+	"financial_assistance/internal/repository"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var errIfMatchRequired = apperror.PreconditionRequired("precondition_required",
+	"If-Match header with the current ETag is required")
+
+// ETags are the record version in quotes. Household members, benefits,
+// criteria and application history share the ETag of the applicant, scheme
+// or application they belong to.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", etag(version))
+}
+
+// ifMatch returns the version named by the If-Match header that every
+// mutation must carry.
+func ifMatch(r *http.Request) (int, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, errIfMatchRequired
+	}
+
+	unquoted, ok := strings.CutPrefix(value, `"`)
+	if ok {
+		unquoted, ok = strings.CutSuffix(unquoted, `"`)
+	}
+	version, err := strconv.Atoi(unquoted)
+	if !ok || err != nil || version < 1 {
+		return 0, fmt.Errorf("%w: If-Match %s is not a current ETag", repository.ErrVersionMismatch, value)
+	}
+	return version, nil
+}
+
+// notModified sets the ETag of a GET response and, if the If-None-Match
+// header already names it, answers 304 Not Modified and reports true.
+func notModified(w http.ResponseWriter, r *http.Request, version int) bool {
+	tag := etag(version)
+	setETag(w, version)
+
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == tag {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"financial_assistance/internal/models"
+	"net/http"
+	"testing"
+)
+
+func TestCreateSetsETag(t *testing.T) {
+	api := newTestAPI(t)
+	var applicant models.Applicant
+	rec := api.create("/api/applicants", applicantBody, &applicant)
+	if got := rec.Header().Get("ETag"); got != `"1"` {
+		t.Errorf("applicant ETag = %q, want \"1\"", got)
+	}
+
+	var scheme models.Scheme
+	rec = api.create("/api/schemes", `{"name": "Universal Grant"}`, &scheme)
+	if got := rec.Header().Get("ETag"); got != `"1"` {
+		t.Errorf("scheme ETag = %q, want \"1\"", got)
+	}
+
+	var application models.Application
+	body := `{"applicant_id": "` + applicant.ID.String() + `", "scheme_id": "` + scheme.ID.String() + `"}`
+	rec = api.create("/api/applications", body, &application)
+	if got := rec.Header().Get("ETag"); got != `"1"` {
+		t.Errorf("application ETag = %q, want \"1\"", got)
+	}
+}
+
+func TestMutationRequiresIfMatch(t *testing.T) {
+	api := newTestAPI(t)
+	var applicant models.Applicant
+	api.create("/api/applicants", applicantBody, &applicant)
+	var scheme models.Scheme
+	api.create("/api/schemes", `{"name": "Universal Grant"}`, &scheme)
+
+	tests := []struct {
+		method, path, body string
+	}{
+		{"PUT", "/api/applicants/" + applicant.ID.String(), applicantBody},
+		{"PATCH", "/api/applicants/" + applicant.ID.String(), `{"monthly_income": 900}`},
+		{"DELETE", "/api/applicants/" + applicant.ID.String(), ""},
+		{"POST", "/api/applicants/" + applicant.ID.String() + "/household",
+			`{"name": "Ken Tan", "sex": "male", "date_of_birth": "1983-07-30", "relation": "spouse"}`},
+		{"PUT", "/api/schemes/" + scheme.ID.String(), `{"name": "Universal Grant"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			expectProblem(t, api.do(tt.method, tt.path, tt.body), http.StatusPreconditionRequired, "precondition_required")
+		})
+	}
+}
+
+func TestMutationWithStaleIfMatch(t *testing.T) {
+	api := newTestAPI(t)
+	var applicant models.Applicant
+	api.create("/api/applicants", applicantBody, &applicant)
+	path := "/api/applicants/" + applicant.ID.String()
+
+	rec := api.do("PATCH", path, `{"monthly_income": 900}`, "If-Match", `"1"`)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"2"` {
+		t.Fatalf("PATCH = %d with ETag %q, want 200 with \"2\"", rec.Code, rec.Header().Get("ETag"))
+	}
+
+	for _, tag := range []string{`"1"`, `"3"`, `W/"2"`, `2`, `"two"`} {
+		rec := api.do("PATCH", path, `{"monthly_income": 1000}`, "If-Match", tag)
+		expectProblem(t, rec, http.StatusPreconditionFailed, "version_mismatch")
+	}
+
+	var stored models.Applicant
+	decode(t, api.do("GET", path, ""), &stored)
+	if stored.MonthlyIncome != 900 || stored.Version != 2 {
+		t.Errorf("stored income %v at version %d, want 900 at 2", stored.MonthlyIncome, stored.Version)
+	}
+}
+
+func TestGetWithIfNoneMatch(t *testing.T) {
+	api := newTestAPI(t)
+	var applicant models.Applicant
+	api.create("/api/applicants", applicantBody, &applicant)
+	path := "/api/applicants/" + applicant.ID.String()
+
+	for _, header := range []string{`"1"`, `W/"1"`, `"7", "1"`, `*`} {
+		rec := api.do("GET", path, "", "If-None-Match", header)
+		if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+			t.Errorf("If-None-Match %s: %d with %d bytes, want 304 without a body", header, rec.Code, rec.Body.Len())
+		}
+		if got := rec.Header().Get("ETag"); got != `"1"` {
+			t.Errorf("If-None-Match %s: ETag = %q, want \"1\"", header, got)
+		}
+	}
+
+	api.do("PATCH", path, `{"monthly_income": 900}`, "If-Match", `"1"`)
+	rec := api.do("GET", path, "", "If-None-Match", `"1"`)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"2"` {
+		t.Errorf("after a change: %d with ETag %q, want 200 with \"2\"", rec.Code, rec.Header().Get("ETag"))
+	}
+}
@@ -2,12 +2,15 @@ package handler
 
 import (
 	"encoding/json"
+	"financial_assistance/internal/apperror"
 	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository"
 	"financial_assistance/internal/service"
 	"fmt"
-	"io"
-	"log"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -24,103 +27,460 @@ func NewHandler(service *service.Service) *Handler {
 
 func (h *Handler) CreateApplicant(w http.ResponseWriter, r *http.Request) {
 	var applicant models.Applicant
+	if err := decodeBody(w, r, &applicant); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := h.service.CreateApplicant(r.Context(), &applicant); err != nil {
+		writeError(w, r, err)
+		return
+	}
 
-	body, err := io.ReadAll(r.Body)
+	setETag(w, applicant.Version)
+	writeCreated(w, "/api/applicants/"+applicant.ID.String(), applicant)
+}
+
+func (h *Handler) GetAllApplicants(w http.ResponseWriter, r *http.Request) {
+	page, err := parsePage(r)
 	if err != nil {
-		log.Printf("Error reading body: %v", err)
-		http.Error(w, "Error reading request body", http.StatusBadRequest)
+		writeError(w, r, err)
+		return
+	}
+	if field := strings.TrimPrefix(page.Sort, "-"); slices.Contains(encryptedSortFields, field) {
+		writeError(w, r, apperror.Validation("unsupported_sort",
+			"applicants cannot be sorted by %s since it is stored encrypted; sort by id instead", field))
 		return
 	}
 
-	log.Printf("Received body: %s", string(body))
+	query := r.URL.Query()
+	filter := repository.ApplicantFilter{
+		EmploymentStatus: models.EmploymentStatus(models.NormalizeEnum(query.Get("employment_status"))),
+		MaritalStatus:    models.MaritalStatus(models.NormalizeEnum(query.Get("marital_status"))),
+		NationalID:       models.NormalizeNationalID(query.Get("national_id")),
+		Name:             models.NormalizeName(query.Get("name")),
+		Page:             page,
+	}
+	if filter.EmploymentStatus != "" && !filter.EmploymentStatus.Valid() {
+		writeError(w, r, apperror.Validation("invalid_parameter", "invalid employment_status %q", filter.EmploymentStatus))
+		return
+	}
+	if filter.MaritalStatus != "" && !filter.MaritalStatus.Valid() {
+		writeError(w, r, apperror.Validation("invalid_parameter", "invalid marital_status %q", filter.MaritalStatus))
+		return
+	}
+	if value := query.Get("date_of_birth"); value != "" {
+		filter.DateOfBirth, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			writeError(w, r, apperror.Validation("invalid_parameter", "invalid date_of_birth %q, expected YYYY-MM-DD", value))
+			return
+		}
+	}
+	// Names are only looked up together with a date of birth, which is what
+	// the name index covers.
+	if (filter.Name == "") != filter.DateOfBirth.IsZero() {
+		writeError(w, r, apperror.Validation("missing_parameter", "name and date_of_birth must be given together"))
+		return
+	}
 
-	err = json.Unmarshal(body, &applicant)
+	applicants, next, err := h.service.GetAllApplicants(r.Context(), filter)
 	if err != nil {
-		log.Printf("Error decoding JSON: %v", err)
-		http.Error(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
-	if err := h.service.CreateApplicant(r.Context(), &applicant); err != nil {
-		log.Printf("Error creating applicant: %v", err)
-		http.Error(w, "Failed to create applicant", http.StatusInternalServerError)
+	writeList(w, applicants, next)
+}
+
+func (h *Handler) GetApplicant(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id", "applicant")
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
+	applicant, err := h.service.GetApplicant(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if notModified(w, r, applicant.Version) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(applicant)
 }
 
-func (h *Handler) GetAllApplicants(w http.ResponseWriter, r *http.Request) {
-	applicants, err := h.service.GetAllApplicants(r.Context())
+func (h *Handler) UpdateApplicant(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id", "applicant")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	version, err := ifMatch(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var applicant models.Applicant
+	if err := decodeBody(w, r, &applicant); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	applicant.ID = id
+	applicant.Version = version
+
+	h.saveApplicant(w, r, &applicant)
+}
+
+// PatchApplicant applies the fields present in the request body over the
+// stored applicant. A "household" field, when present, replaces the whole
+// household.
+func (h *Handler) PatchApplicant(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id", "applicant")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	version, err := ifMatch(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	applicant, err := h.service.GetApplicant(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if applicant.Version != version {
+		writeError(w, r, repository.ErrVersionMismatch)
+		return
+	}
+
+	body, err := readBody(w, r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		writeError(w, r, invalidBody(err))
+		return
+	}
+	// Decoding into the stored members would keep the fields a new member
+	// leaves out, so the household is cleared first.
+	if _, ok := fields["household"]; ok {
+		applicant.HouseholdMembers = nil
+	}
+	if err := json.Unmarshal(body, applicant); err != nil {
+		writeError(w, r, invalidBody(err))
+		return
+	}
+	applicant.ID = id
+	applicant.Version = version
+
+	h.saveApplicant(w, r, applicant)
+}
+
+func (h *Handler) saveApplicant(w http.ResponseWriter, r *http.Request, applicant *models.Applicant) {
+	if err := h.service.UpdateApplicant(r.Context(), applicant); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, applicant.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(applicant)
+}
+
+func (h *Handler) DeleteApplicant(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id", "applicant")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	version, err := ifMatch(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := h.service.DeleteApplicant(r.Context(), id, version); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) GetHousehold(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id", "applicant")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	applicant, err := h.service.GetApplicant(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if notModified(w, r, applicant.Version) {
+		return
+	}
+
+	members := applicant.HouseholdMembers
+	if members == nil {
+		members = []models.HouseholdMember{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(members)
+}
+
+func (h *Handler) AddHouseholdMember(w http.ResponseWriter, r *http.Request) {
+	applicantID, err := pathID(r, "id", "applicant")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	version, err := ifMatch(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var member models.HouseholdMember
+	if err := decodeBody(w, r, &member); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	version, err = h.service.AddHouseholdMember(r.Context(), applicantID, &member, version)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, version)
+	writeCreated(w, fmt.Sprintf("/api/applicants/%s/household/%s", applicantID, member.ID), member)
+}
+
+func (h *Handler) GetHouseholdMember(w http.ResponseWriter, r *http.Request) {
+	applicantID, err := pathID(r, "id", "applicant")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	memberID, err := pathID(r, "memberID", "household member")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	member, version, err := h.service.GetHouseholdMember(r.Context(), applicantID, memberID)
 	if err != nil {
-		http.Error(w, "Failed to get applicants", http.StatusInternalServerError)
+		writeError(w, r, err)
+		return
+	}
+	if notModified(w, r, version) {
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(applicants)
+	json.NewEncoder(w).Encode(member)
+}
+
+func (h *Handler) UpdateHouseholdMember(w http.ResponseWriter, r *http.Request) {
+	applicantID, err := pathID(r, "id", "applicant")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	memberID, err := pathID(r, "memberID", "household member")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	version, err := ifMatch(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var member models.HouseholdMember
+	if err := decodeBody(w, r, &member); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	member.ID = memberID
+
+	version, err = h.service.UpdateHouseholdMember(r.Context(), applicantID, &member, version)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(member)
+}
+
+func (h *Handler) DeleteHouseholdMember(w http.ResponseWriter, r *http.Request) {
+	applicantID, err := pathID(r, "id", "applicant")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	memberID, err := pathID(r, "memberID", "household member")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	version, err := ifMatch(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	version, err = h.service.DeleteHouseholdMember(r.Context(), applicantID, memberID, version)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, version)
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func (h *Handler) CreateApplication(w http.ResponseWriter, r *http.Request) {
 	var application models.Application
+	if err := decodeBody(w, r, &application); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := h.service.CreateApplication(r.Context(), &application); err != nil {
+		writeError(w, r, err)
+		return
+	}
 
-	body, err := io.ReadAll(r.Body)
+	setETag(w, application.Version)
+	writeCreated(w, "/api/applications/"+application.ID.String(), application)
+}
+
+func (h *Handler) GetApplication(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id", "application")
 	if err != nil {
-		log.Printf("Error reading body: %v", err)
-		http.Error(w, "Error reading request body", http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
-	log.Printf("Received application JSON: %s", string(body))
+	application, err := h.service.GetApplication(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if notModified(w, r, application.Version) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(application)
+}
 
-	err = json.Unmarshal(body, &application)
+func (h *Handler) TransitionApplication(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id", "application")
 	if err != nil {
-		log.Printf("Error decoding application JSON: %v", err)
-		log.Printf("Problematic JSON: %s", string(body))
-		http.Error(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		writeError(w, r, err)
+		return
+	}
+	version, err := ifMatch(r)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	log.Printf("Decoded Application: %+v", application)
+	var transition service.TransitionRequest
+	if err := decodeBody(w, r, &transition); err != nil {
+		writeError(w, r, err)
+		return
+	}
 
-	if err := h.service.CreateApplication(r.Context(), &application); err != nil {
-		log.Printf("Error creating application: %v", err)
-		http.Error(w, "Failed to create application", http.StatusInternalServerError)
+	application, err := h.service.TransitionApplication(r.Context(), id, transition, version)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
+	setETag(w, application.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(application)
 }
 
-func (h *Handler) GetAllSchemes(w http.ResponseWriter, r *http.Request) {
-	schemes, err := h.service.GetAllSchemes(r.Context())
+func (h *Handler) GetApplicationHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id", "application")
 	if err != nil {
-		log.Printf("Error getting schemes: %v", err)
-		http.Error(w, "Failed to get schemes", http.StatusInternalServerError)
+		writeError(w, r, err)
+		return
+	}
+
+	events, version, err := h.service.GetApplicationHistory(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if notModified(w, r, version) {
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(schemes)
+	json.NewEncoder(w).Encode(events)
 }
 
-func (h *Handler) GetEligibleSchemes(w http.ResponseWriter, r *http.Request) {
-	applicantID := r.URL.Query().Get("applicant")
-	if applicantID == "" {
-		http.Error(w, "Applicant ID is required", http.StatusBadRequest)
+func (h *Handler) GetAllSchemes(w http.ResponseWriter, r *http.Request) {
+	page, err := parsePage(r)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	id, err := uuid.Parse(applicantID)
+	schemes, next, err := h.service.GetAllSchemes(r.Context(), repository.SchemeFilter{Page: page})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeList(w, schemes, next)
+}
+
+func (h *Handler) GetEligibleSchemes(w http.ResponseWriter, r *http.Request) {
+	id, err := parseOptionalUUID(r, "applicant")
 	if err != nil {
-		http.Error(w, "Invalid applicant ID format", http.StatusBadRequest)
+		writeError(w, r, err)
+		return
+	}
+	if id == uuid.Nil {
+		writeError(w, r, apperror.Validation("missing_parameter", "applicant query parameter is required"))
 		return
 	}
 
-	schemes, err := h.service.GetEligibleSchemes(r.Context(), id)
+	asOf := time.Now()
+	if value := r.URL.Query().Get("as_of"); value != "" {
+		asOf, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			writeError(w, r, apperror.Validation("invalid_parameter", "invalid as_of date %q, expected YYYY-MM-DD", value))
+			return
+		}
+	}
+
+	schemes, err := h.service.GetEligibleSchemes(r.Context(), id, asOf)
 	if err != nil {
-		log.Printf("Error getting eligible schemes: %v", err)
-		http.Error(w, "Failed to get eligible schemes", http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -129,56 +489,240 @@ func (h *Handler) GetEligibleSchemes(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) GetAllApplications(w http.ResponseWriter, r *http.Request) {
-	applications, err := h.service.GetAllApplications(r.Context())
+	page, err := parsePage(r)
 	if err != nil {
-		log.Printf("Error getting applications: %v", err)
-		http.Error(w, "Failed to get applications", http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(applications)
+	filter := repository.ApplicationFilter{
+		Status: models.ApplicationStatus(r.URL.Query().Get("status")),
+		Page:   page,
+	}
+	if filter.Status != "" && !filter.Status.Valid() {
+		writeError(w, r, apperror.Validation("invalid_parameter", "invalid status %q", filter.Status))
+		return
+	}
+	if filter.ApplicantID, err = parseOptionalUUID(r, "applicant_id"); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if filter.SchemeID, err = parseOptionalUUID(r, "scheme_id"); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	applications, next, err := h.service.GetAllApplications(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeList(w, applications, next)
 }
 
 func (h *Handler) CreateScheme(w http.ResponseWriter, r *http.Request) {
 	var scheme models.Scheme
+	if err := decodeBody(w, r, &scheme); err != nil {
+		writeError(w, r, err)
+		return
+	}
 
-	body, err := io.ReadAll(r.Body)
+	if err := h.service.CreateScheme(r.Context(), &scheme); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, scheme.Version)
+	writeCreated(w, "/api/schemes/"+scheme.ID.String(), scheme)
+}
+
+func (h *Handler) GetScheme(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id", "scheme")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	scheme, err := h.service.GetScheme(r.Context(), id)
 	if err != nil {
-		log.Printf("Error reading body: %v", err)
-		http.Error(w, "Error reading request body", http.StatusBadRequest)
+		writeError(w, r, err)
+		return
+	}
+	if notModified(w, r, scheme.Version) {
 		return
 	}
 
-	log.Printf("Received JSON: %s", string(body))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(scheme)
+}
 
-	err = json.Unmarshal(body, &scheme)
+func (h *Handler) UpdateScheme(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id", "scheme")
 	if err != nil {
-		log.Printf("Error decoding scheme: %v", err)
-		log.Printf("Scheme JSON: %s", string(body))
-		http.Error(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		writeError(w, r, err)
+		return
+	}
+	version, err := ifMatch(r)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	log.Printf("Decoded Scheme: %+v", scheme)
-	log.Printf("Criteria Has Children: %v", scheme.Criteria.HasChildren)
+	var scheme models.Scheme
+	if err := decodeBody(w, r, &scheme); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	scheme.ID = id
+	scheme.Version = version
 
-	if scheme.Name == "" {
-		http.Error(w, "Scheme name is required", http.StatusBadRequest)
+	if err := h.service.UpdateScheme(r.Context(), &scheme); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	if scheme.ID == uuid.Nil {
-		scheme.ID = uuid.New()
+	setETag(w, scheme.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(scheme)
+}
+
+func (h *Handler) DeleteScheme(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id", "scheme")
+	if err != nil {
+		writeError(w, r, err)
+		return
 	}
 
-	if err := h.service.CreateScheme(r.Context(), &scheme); err != nil {
-		log.Printf("Error creating scheme: %v", err)
-		http.Error(w, "Failed to create scheme", http.StatusInternalServerError)
+	version, err := ifMatch(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := h.service.DeleteScheme(r.Context(), id, version); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) UpdateCriteria(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id", "scheme")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	version, err := ifMatch(r)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
+	var criteria models.Criteria
+	if err := decodeBody(w, r, &criteria); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	version, err = h.service.UpdateCriteria(r.Context(), id, &criteria, version)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(criteria)
+}
+
+func (h *Handler) AddBenefit(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id", "scheme")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	version, err := ifMatch(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var benefit models.Benefit
+	if err := decodeBody(w, r, &benefit); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	version, err = h.service.AddBenefit(r.Context(), id, &benefit, version)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, version)
+	writeCreated(w, fmt.Sprintf("/api/schemes/%s/benefits/%s", id, benefit.ID), benefit)
+}
+
+func (h *Handler) GetBenefit(w http.ResponseWriter, r *http.Request) {
+	schemeID, err := pathID(r, "id", "scheme")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	benefitID, err := pathID(r, "benefitID", "benefit")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	benefit, version, err := h.service.GetBenefit(r.Context(), schemeID, benefitID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if notModified(w, r, version) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(benefit)
+}
+
+func (h *Handler) DeleteBenefit(w http.ResponseWriter, r *http.Request) {
+	schemeID, err := pathID(r, "id", "scheme")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	benefitID, err := pathID(r, "benefitID", "benefit")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	version, err := ifMatch(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	version, err = h.service.DeleteBenefit(r.Context(), schemeID, benefitID, version)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, version)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeCreated answers a successful create with the new resource and its
+// URL.
+func writeCreated(w http.ResponseWriter, location string, v any) {
+	w.Header().Set("Location", location)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(scheme)
+	json.NewEncoder(w).Encode(v)
 }
@@ -0,0 +1,263 @@
+package handler
+
+import (
+	"encoding/json"
+	"financial_assistance/internal/auth"
+	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository/memory"
+	"financial_assistance/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// testAPI serves the API routes, as cmd/api registers them, over in-memory
+// repositories. Requests are made as a user with the given name and role, as
+// if authenticated.
+type testAPI struct {
+	t      *testing.T
+	store  *memory.Store
+	router *mux.Router
+	name   string
+	role   models.Role
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	store := memory.NewStore()
+	svc := service.NewService(memory.NewApplicantRepo(store), memory.NewSchemeRepo(store), memory.NewApplicationRepo(store))
+	h := NewHandler(svc)
+	idem := NewIdempotency(memory.NewIdempotencyRepo(store), time.Hour)
+
+	api := &testAPI{t: t, store: store, name: "tester", role: models.RoleAdmin}
+	r := mux.NewRouter()
+	r.Use(api.authenticate)
+	r.HandleFunc("/api/applicants", h.GetAllApplicants).Methods("GET")
+	r.Handle("/api/applicants", idem.Wrap(http.HandlerFunc(h.CreateApplicant))).Methods("POST")
+	r.HandleFunc("/api/applicants/{id}", h.GetApplicant).Methods("GET")
+	r.HandleFunc("/api/applicants/{id}", h.UpdateApplicant).Methods("PUT")
+	r.HandleFunc("/api/applicants/{id}", h.PatchApplicant).Methods("PATCH")
+	r.HandleFunc("/api/applicants/{id}", h.DeleteApplicant).Methods("DELETE")
+	r.HandleFunc("/api/applicants/{id}/household", h.GetHousehold).Methods("GET")
+	r.HandleFunc("/api/applicants/{id}/household", h.AddHouseholdMember).Methods("POST")
+	r.HandleFunc("/api/applicants/{id}/household/{memberID}", h.GetHouseholdMember).Methods("GET")
+	r.HandleFunc("/api/applicants/{id}/household/{memberID}", h.UpdateHouseholdMember).Methods("PUT")
+	r.HandleFunc("/api/applicants/{id}/household/{memberID}", h.DeleteHouseholdMember).Methods("DELETE")
+	r.HandleFunc("/api/schemes", h.GetAllSchemes).Methods("GET")
+	r.Handle("/api/schemes", idem.Wrap(http.HandlerFunc(h.CreateScheme))).Methods("POST")
+	r.HandleFunc("/api/schemes/eligible", h.GetEligibleSchemes).Methods("GET")
+	r.HandleFunc("/api/schemes/{id}", h.GetScheme).Methods("GET")
+	r.HandleFunc("/api/schemes/{id}", h.UpdateScheme).Methods("PUT")
+	r.HandleFunc("/api/schemes/{id}", h.DeleteScheme).Methods("DELETE")
+	r.HandleFunc("/api/schemes/{id}/criteria", h.UpdateCriteria).Methods("PUT")
+	r.HandleFunc("/api/schemes/{id}/benefits", h.AddBenefit).Methods("POST")
+	r.HandleFunc("/api/schemes/{id}/benefits/{benefitID}", h.GetBenefit).Methods("GET")
+	r.HandleFunc("/api/schemes/{id}/benefits/{benefitID}", h.DeleteBenefit).Methods("DELETE")
+	r.HandleFunc("/api/applications", h.GetAllApplications).Methods("GET")
+	r.Handle("/api/applications", idem.Wrap(http.HandlerFunc(h.CreateApplication))).Methods("POST")
+	r.HandleFunc("/api/applications/{id}", h.GetApplication).Methods("GET")
+	r.HandleFunc("/api/applications/{id}/transitions", h.TransitionApplication).Methods("POST")
+	r.HandleFunc("/api/applications/{id}/history", h.GetApplicationHistory).Methods("GET")
+	api.router = r
+	return api
+}
+
+func (a *testAPI) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal := &auth.Principal{Kind: auth.PrincipalUser, ID: uuid.New(), Name: a.name, Role: a.role}
+		next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), principal)))
+	})
+}
+
+// do sends a request with an optional JSON body and headers given as name,
+// value pairs.
+func (a *testAPI) do(method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	a.t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Add(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	a.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// create posts body to path, expects 201 Created and decodes the response
+// into v.
+func (a *testAPI) create(path, body string, v any) *httptest.ResponseRecorder {
+	a.t.Helper()
+	rec := a.do("POST", path, body)
+	if rec.Code != http.StatusCreated {
+		a.t.Fatalf("POST %s = %d %s, want 201", path, rec.Code, rec.Body)
+	}
+	decode(a.t, rec, v)
+	return rec
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("decoding %s: %v", rec.Body, err)
+	}
+}
+
+// problem is the body of an error response.
+type problem struct {
+	Status int    `json:"status"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+	Errors []struct {
+		Field string `json:"field"`
+		Code  string `json:"code"`
+	} `json:"errors"`
+	FailedCriteria []string `json:"failed_criteria"`
+}
+
+// fields returns the field errors of p as field: code.
+func (p problem) fields() []string {
+	var fields []string
+	for _, e := range p.Errors {
+		fields = append(fields, e.Field+": "+e.Code)
+	}
+	return fields
+}
+
+// expectProblem checks that rec is an RFC 7807 response with the status and
+// code given and returns it.
+func expectProblem(t *testing.T, rec *httptest.ResponseRecorder, status int, code string) problem {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status = %d %s, want %d", rec.Code, rec.Body, status)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("Content-Type = %q, want application/problem+json", ct)
+	}
+	var p problem
+	decode(t, rec, &p)
+	if p.Status != status || p.Code != code {
+		t.Errorf("problem = %d %q, want %d %q", p.Status, p.Code, status, code)
+	}
+	return p
+}
+
+const applicantBody = `{
+	"name": "Mary Tan",
+	"employment_status": "unemployed",
+	"marital_status": "married",
+	"sex": "female",
+	"date_of_birth": "1985-03-14",
+	"monthly_income": 800,
+	"household": [{
+		"name": "Gwen Tan",
+		"employment_status": "unemployed",
+		"sex": "female",
+		"date_of_birth": "2016-02-01",
+		"relation": "daughter",
+		"school_level": "primary",
+		"monthly_income": 0
+	}]
+}`
+
+func TestPatchApplicantKeepsOmittedFields(t *testing.T) {
+	api := newTestAPI(t)
+	var created models.Applicant
+	api.create("/api/applicants", applicantBody, &created)
+
+	rec := api.do("PATCH", "/api/applicants/"+created.ID.String(), `{"monthly_income": 1200}`, "If-Match", `"1"`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PATCH = %d %s", rec.Code, rec.Body)
+	}
+	var patched models.Applicant
+	decode(t, rec, &patched)
+
+	if patched.MonthlyIncome != 1200 {
+		t.Errorf("monthly_income = %v, want 1200", patched.MonthlyIncome)
+	}
+	if patched.Name != created.Name || !patched.DateOfBirth.Equal(created.DateOfBirth) {
+		t.Errorf("patched applicant = %q born %v, want %q born %v", patched.Name, patched.DateOfBirth, created.Name, created.DateOfBirth)
+	}
+	if len(patched.HouseholdMembers) != 1 || patched.HouseholdMembers[0].ID != created.HouseholdMembers[0].ID {
+		t.Errorf("household = %+v, want the original member", patched.HouseholdMembers)
+	}
+}
+
+func TestPatchApplicantReplacesHousehold(t *testing.T) {
+	api := newTestAPI(t)
+	var created models.Applicant
+	api.create("/api/applicants", applicantBody, &created)
+
+	body := `{"household": [{"name": "Ken Tan", "sex": "male", "date_of_birth": "1983-07-30", "relation": "spouse"}]}`
+	rec := api.do("PATCH", "/api/applicants/"+created.ID.String(), body, "If-Match", `"1"`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PATCH = %d %s", rec.Code, rec.Body)
+	}
+	var patched models.Applicant
+	decode(t, rec, &patched)
+
+	if len(patched.HouseholdMembers) != 1 {
+		t.Fatalf("household has %d members, want 1", len(patched.HouseholdMembers))
+	}
+	member := patched.HouseholdMembers[0]
+	old := created.HouseholdMembers[0]
+	if member.ID == old.ID {
+		t.Errorf("new member kept the ID %s of the member it replaced", old.ID)
+	}
+	if member.Name != "Ken Tan" || member.Relation != models.RelationSpouse {
+		t.Errorf("member = %q (%s), want Ken Tan (spouse)", member.Name, member.Relation)
+	}
+	if member.SchoolLevel != "" || member.EmploymentStatus != "" {
+		t.Errorf("member kept school_level %q and employment_status %q of the replaced member", member.SchoolLevel, member.EmploymentStatus)
+	}
+	if want := time.Date(1983, time.July, 30, 0, 0, 0, 0, time.UTC); !member.DateOfBirth.Equal(want) {
+		t.Errorf("date_of_birth = %v, want %v", member.DateOfBirth, want)
+	}
+}
+
+func TestPatchApplicantClearsHousehold(t *testing.T) {
+	api := newTestAPI(t)
+	var created models.Applicant
+	api.create("/api/applicants", applicantBody, &created)
+
+	rec := api.do("PATCH", "/api/applicants/"+created.ID.String(), `{"household": []}`, "If-Match", `"1"`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PATCH = %d %s", rec.Code, rec.Body)
+	}
+	var patched models.Applicant
+	decode(t, rec, &patched)
+	if len(patched.HouseholdMembers) != 0 {
+		t.Errorf("household = %+v, want none", patched.HouseholdMembers)
+	}
+}
+
+func TestForbiddenRoles(t *testing.T) {
+	tests := []struct {
+		role         models.Role
+		method, path string
+		body         string
+	}{
+		{models.RoleAuditor, "POST", "/api/schemes", `{"name": "Universal Grant"}`},
+		{models.RoleAuditor, "POST", "/api/applicants", applicantBody},
+		{models.RoleCaseworker, "POST", "/api/schemes", `{"name": "Universal Grant"}`},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role)+" "+tt.method+" "+tt.path, func(t *testing.T) {
+			api := newTestAPI(t)
+			api.role = tt.role
+			expectProblem(t, api.do(tt.method, tt.path, tt.body), http.StatusForbidden, "forbidden")
+		})
+	}
+
+	api := newTestAPI(t)
+	var scheme models.Scheme
+	api.create("/api/schemes", `{"name": "Universal Grant"}`, &scheme)
+	api.role = models.RoleCaseworker
+	rec := api.do("PUT", "/api/schemes/"+scheme.ID.String(), `{"name": "Renamed Grant"}`, "If-Match", `"1"`)
+	expectProblem(t, rec, http.StatusForbidden, "forbidden")
+}
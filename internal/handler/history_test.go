@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"financial_assistance/internal/models"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestApplicationHistory(t *testing.T) {
+	api := newTestAPI(t)
+	start := time.Now().UTC().Add(-time.Second)
+	api.name = "alice"
+	application := newApplication(api)
+
+	api.name = "bob"
+	api.role = models.RoleCaseworker
+	moves := []struct {
+		status, reason string
+	}{
+		{"submitted", ""},
+		{"under_review", "documents_received"},
+		{"rejected", "income_above_ceiling"},
+	}
+	for _, move := range moves {
+		path := "/api/applications/" + application.ID.String() + "/transitions"
+		body := `{"status": "` + move.status + `", "reason_code": "` + move.reason + `"}`
+		rec := api.do("POST", path, body, "If-Match", etag(application.Version))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("move to %s = %d %s", move.status, rec.Code, rec.Body)
+		}
+		decode(t, rec, &application)
+	}
+
+	api.name = "carol"
+	api.role = models.RoleAuditor
+	rec := api.do("GET", "/api/applications/"+application.ID.String()+"/history", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET history as auditor = %d %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("ETag"); got != etag(application.Version) {
+		t.Errorf("ETag = %q, want the application's %q", got, etag(application.Version))
+	}
+	var events []models.ApplicationEvent
+	decode(t, rec, &events)
+
+	want := []models.ApplicationEvent{
+		{ToStatus: models.StatusDraft, Actor: "user:alice"},
+		{FromStatus: models.StatusDraft, ToStatus: models.StatusSubmitted, Actor: "user:bob"},
+		{FromStatus: models.StatusSubmitted, ToStatus: models.StatusUnderReview, Actor: "user:bob", ReasonCode: "documents_received"},
+		{FromStatus: models.StatusUnderReview, ToStatus: models.StatusRejected, Actor: "user:bob", ReasonCode: "income_above_ceiling"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("history has %d events, want %d: %+v", len(events), len(want), events)
+	}
+	ids := make(map[uuid.UUID]bool)
+	previous := start
+	for i, e := range events {
+		w := want[i]
+		if e.FromStatus != w.FromStatus || e.ToStatus != w.ToStatus || e.Actor != w.Actor || e.ReasonCode != w.ReasonCode {
+			t.Errorf("event %d = %s -> %s by %s (%q), want %s -> %s by %s (%q)", i,
+				e.FromStatus, e.ToStatus, e.Actor, e.ReasonCode, w.FromStatus, w.ToStatus, w.Actor, w.ReasonCode)
+		}
+		if e.ApplicationID != application.ID || e.ID == uuid.Nil || ids[e.ID] {
+			t.Errorf("event %d has ID %s for application %s, want a new ID for %s", i, e.ID, e.ApplicationID, application.ID)
+		}
+		ids[e.ID] = true
+		if e.CreatedAt.Before(previous) || e.CreatedAt.After(time.Now()) {
+			t.Errorf("event %d at %v, want between %v and now", i, e.CreatedAt, previous)
+		}
+		previous = e.CreatedAt
+	}
+	if !events[len(events)-1].CreatedAt.Equal(application.UpdatedAt) {
+		t.Errorf("last event at %v, application updated at %v", events[len(events)-1].CreatedAt, application.UpdatedAt)
+	}
+
+	// A refused move leaves no trace.
+	api.role = models.RoleAdmin
+	api.transition(application, "approved", etag(application.Version))
+	decode(t, api.do("GET", "/api/applications/"+application.ID.String()+"/history", ""), &events)
+	if len(events) != len(want) {
+		t.Errorf("history has %d events after a refused move, want %d", len(events), len(want))
+	}
+}
+
+func TestApplicationHistoryNotFound(t *testing.T) {
+	api := newTestAPI(t)
+	api.role = models.RoleAuditor
+	rec := api.do("GET", "/api/applications/"+uuid.NewString()+"/history", "")
+	expectProblem(t, rec, http.StatusNotFound, "application_not_found")
+
+	expectProblem(t, api.do("GET", "/api/applications/42/history", ""), http.StatusBadRequest, "invalid_id")
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"financial_assistance/internal/apperror"
+	"financial_assistance/internal/auth"
+	"financial_assistance/internal/logging"
+	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const maxIdempotencyKeyLength = 255
+
+var (
+	errInvalidIdempotencyKey = apperror.Validation("invalid_idempotency_key",
+		"Idempotency-Key must be between 1 and %d characters", maxIdempotencyKeyLength)
+	errIdempotencyKeyReused = apperror.Unprocessable("idempotency_key_reused",
+		"Idempotency-Key was already used with a different request")
+	errIdempotencyInProgress = apperror.Conflict("idempotency_request_in_progress",
+		"a request with this Idempotency-Key is still in progress")
+)
+
+// Idempotency makes POST requests carrying an Idempotency-Key header safe to
+// retry: the first response is stored and replayed for repeats of the same
+// request until the key expires.
+type Idempotency struct {
+	repo repository.IdempotencyRepository
+	ttl  time.Duration
+}
+
+func NewIdempotency(repo repository.IdempotencyRepository, ttl time.Duration) *Idempotency {
+	return &Idempotency{repo: repo, ttl: ttl}
+}
+
+func (m *Idempotency) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		values, ok := r.Header["Idempotency-Key"]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key := values[0]
+		if len(values) > 1 || key == "" || len(key) > maxIdempotencyKeyLength {
+			writeError(w, r, errInvalidIdempotencyKey)
+			return
+		}
+
+		body, err := readBody(w, r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Keys are scoped to the caller, so that one client cannot replay
+		// another's response by guessing its key.
+		scope := r.Method + " " + r.URL.Path
+		if principal, ok := auth.FromContext(r.Context()); ok {
+			scope = principal.Subject() + " " + scope
+		}
+
+		now := time.Now().UTC()
+		record := &models.IdempotencyRecord{
+			Scope:       scope,
+			Key:         key,
+			Fingerprint: fingerprint(r, body),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(m.ttl),
+		}
+
+		existing, err := m.repo.ReserveIdempotencyKey(r.Context(), record)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if existing != nil {
+			switch {
+			case existing.Fingerprint != record.Fingerprint:
+				writeError(w, r, errIdempotencyKeyReused)
+			case !existing.Completed():
+				writeError(w, r, errIdempotencyInProgress)
+			default:
+				replay(w, existing)
+			}
+			return
+		}
+
+		// The outcome is saved even if the client has gone away, since that
+		// is exactly when it will retry.
+		ctx := context.WithoutCancel(r.Context())
+
+		// A handler that panics must not leave the key reserved, or retries
+		// would be refused as in progress until the key expires.
+		defer func() {
+			if p := recover(); p != nil {
+				if err := m.repo.ReleaseIdempotencyKey(ctx, record.Scope, record.Key); err != nil {
+					logging.FromContext(ctx).Error("releasing idempotency key",
+						"method", r.Method, "path", r.URL.Path, "error", err)
+				}
+				panic(p)
+			}
+		}()
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		if rec.status >= http.StatusInternalServerError {
+			err = m.repo.ReleaseIdempotencyKey(ctx, record.Scope, record.Key)
+		} else {
+			record.StatusCode = rec.status
+			record.ContentType = rec.Header().Get("Content-Type")
+			record.Location = rec.Header().Get("Location")
+			record.ETag = rec.Header().Get("ETag")
+			record.Body = rec.body.Bytes()
+			err = m.repo.CompleteIdempotencyKey(ctx, record)
+		}
+		if err != nil {
+			logging.FromContext(ctx).Error("saving idempotency key",
+				"method", r.Method, "path", r.URL.Path, "error", err)
+		}
+	})
+}
+
+// fingerprint identifies a request by its method, path and body, so that a
+// key reused for a different request can be told apart from a retry.
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method)
+	h.Write([]byte{0})
+	io.WriteString(h, r.URL.Path)
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func replay(w http.ResponseWriter, record *models.IdempotencyRecord) {
+	if record.ContentType != "" {
+		w.Header().Set("Content-Type", record.ContentType)
+	}
+	if record.Location != "" {
+		w.Header().Set("Location", record.Location)
+	}
+	if record.ETag != "" {
+		w.Header().Set("ETag", record.ETag)
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(record.Body)))
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(record.StatusCode)
+	w.Write(record.Body)
+}
+
+// responseRecorder passes a response through while keeping a copy of its
+// status and body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if !rec.wroteHeader {
+		rec.WriteHeader(http.StatusOK)
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
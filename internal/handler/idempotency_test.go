@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"financial_assistance/internal/repository/memory"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	api := newTestAPI(t)
+	first := api.do("POST", "/api/applicants", applicantBody, "Idempotency-Key", "create-mary")
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first POST = %d %s", first.Code, first.Body)
+	}
+	second := api.do("POST", "/api/applicants", applicantBody, "Idempotency-Key", "create-mary")
+
+	if second.Code != http.StatusCreated || second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("repeat = %d replayed %q, want a replayed 201", second.Code, second.Header().Get("Idempotent-Replayed"))
+	}
+	if second.Body.String() != first.Body.String() || second.Header().Get("Location") != first.Header().Get("Location") {
+		t.Errorf("replayed %s at %s, want %s at %s", second.Body, second.Header().Get("Location"), first.Body, first.Header().Get("Location"))
+	}
+	if got := second.Header().Get("ETag"); got != `"1"` || got != first.Header().Get("ETag") {
+		t.Errorf("replayed ETag = %q, want %q as first sent", got, first.Header().Get("ETag"))
+	}
+
+	// The replayed ETag is good for the next update.
+	rec := api.do("PATCH", second.Header().Get("Location"), `{"monthly_income": 900}`, "If-Match", second.Header().Get("ETag"))
+	if rec.Code != http.StatusOK {
+		t.Errorf("PATCH with the replayed ETag = %d %s, want 200", rec.Code, rec.Body)
+	}
+}
+
+func TestIdempotencyKeyReusedForAnotherRequest(t *testing.T) {
+	api := newTestAPI(t)
+	api.do("POST", "/api/schemes", `{"name": "Retrenchment Assistance"}`, "Idempotency-Key", "k1")
+	rec := api.do("POST", "/api/schemes", `{"name": "Another Scheme"}`, "Idempotency-Key", "k1")
+	expectProblem(t, rec, http.StatusUnprocessableEntity, "idempotency_key_reused")
+}
+
+// blockingHandler signals on started when a request reaches it and answers
+// once release is closed.
+type blockingHandler struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h *blockingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.started <- struct{}{}
+	<-h.release
+	w.WriteHeader(http.StatusCreated)
+}
+
+func post(handler http.Handler, body, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/api/things", strings.NewReader(body))
+	req.Header.Set("Idempotency-Key", key)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotencyRequestInProgress(t *testing.T) {
+	next := &blockingHandler{started: make(chan struct{}), release: make(chan struct{})}
+	handler := NewIdempotency(memory.NewIdempotencyRepo(memory.NewStore()), time.Hour).Wrap(next)
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- post(handler, `{}`, "k1") }()
+	<-next.started
+
+	expectProblem(t, post(handler, `{}`, "k1"), http.StatusConflict, "idempotency_request_in_progress")
+
+	close(next.release)
+	if rec := <-done; rec.Code != http.StatusCreated {
+		t.Errorf("first request = %d, want 201", rec.Code)
+	}
+}
+
+func TestIdempotencyKeyExpires(t *testing.T) {
+	calls := 0
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+	})
+	ttl := 100 * time.Millisecond
+	handler := NewIdempotency(memory.NewIdempotencyRepo(memory.NewStore()), ttl).Wrap(next)
+
+	post(handler, `{}`, "k1")
+	if rec := post(handler, `{}`, "k1"); rec.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("repeat within the TTL was not replayed")
+	}
+	time.Sleep(2 * ttl)
+	if rec := post(handler, `{}`, "k1"); rec.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("repeat after the TTL was replayed")
+	}
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want 2", calls)
+	}
+}
+
+func TestIdempotencyReleasesKeyOnPanic(t *testing.T) {
+	panics := true
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if panics {
+			panic("boom")
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	handler := NewIdempotency(memory.NewIdempotencyRepo(memory.NewStore()), time.Hour).Wrap(next)
+
+	func() {
+		defer func() {
+			if p := recover(); p != "boom" {
+				t.Errorf("recovered %v, want the handler's panic", p)
+			}
+		}()
+		post(handler, `{}`, "k1")
+	}()
+
+	panics = false
+	if rec := post(handler, `{}`, "k1"); rec.Code != http.StatusCreated {
+		t.Errorf("retry after a panic = %d %s, want 201", rec.Code, rec.Body)
+	}
+}
+
+func TestIdempotencyLimitsBody(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler ran for an oversized body")
+	})
+	handler := NewIdempotency(memory.NewIdempotencyRepo(memory.NewStore()), time.Hour).Wrap(next)
+
+	body := `"` + strings.Repeat("a", maxBodySize) + `"`
+	expectProblem(t, post(handler, body, "k1"), http.StatusRequestEntityTooLarge, "body_too_large")
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"encoding/json"
+	"financial_assistance/internal/apperror"
+	"financial_assistance/internal/repository"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// encryptedSortFields are the applicant fields that could be sorted by until
+// names and dates of birth were stored encrypted. Asking for them is reported
+// with its own code rather than as an unknown sort field.
+var encryptedSortFields = []string{"name", "date_of_birth"}
+
+type listResponse[T any] struct {
+	Data       []T    `json:"data"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+func writeList[T any](w http.ResponseWriter, items []T, nextCursor string) {
+	if items == nil {
+		items = []T{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(listResponse[T]{Data: items, NextCursor: nextCursor})
+}
+
+// parsePage reads the limit, cursor and sort query parameters shared by all
+// list endpoints.
+func parsePage(r *http.Request) (repository.Page, error) {
+	query := r.URL.Query()
+	page := repository.Page{
+		Cursor: query.Get("cursor"),
+		Sort:   query.Get("sort"),
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return page, apperror.Validation("invalid_page", "invalid limit %q", value)
+		}
+		page.Limit = limit
+	}
+
+	return page, nil
+}
+
+func parseOptionalUUID(r *http.Request, name string) (uuid.UUID, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return uuid.Nil, nil
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, apperror.Validation("invalid_parameter", "invalid %s %q", name, value)
+	}
+	return id, nil
+}
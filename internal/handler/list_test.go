@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"financial_assistance/internal/models"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// list is the body of a list response.
+type list[T any] struct {
+	Data       []T    `json:"data"`
+	NextCursor string `json:"next_cursor"`
+}
+
+// listAll follows next_cursor from the first page of path, which must not
+// have a cursor yet, and returns every item listed.
+func listAll[T any](api *testAPI, path string) []T {
+	api.t.Helper()
+	var all []T
+	cursor := ""
+	for {
+		page := path
+		if cursor != "" {
+			page += "&cursor=" + url.QueryEscape(cursor)
+		}
+		rec := api.do("GET", page, "")
+		if rec.Code != http.StatusOK {
+			api.t.Fatalf("GET %s = %d %s", page, rec.Code, rec.Body)
+		}
+		var l list[T]
+		decode(api.t, rec, &l)
+		all = append(all, l.Data...)
+		if l.NextCursor == "" {
+			return all
+		}
+		cursor = l.NextCursor
+	}
+}
+
+func TestListFollowsNextCursor(t *testing.T) {
+	api := newTestAPI(t)
+	var want []string
+	for _, name := range []string{"Beta", "Alpha", "Beta", "Gamma", "Alpha"} {
+		var scheme models.Scheme
+		api.create("/api/schemes", `{"name": "`+name+`"}`, &scheme)
+		want = append(want, name)
+	}
+	slices.Sort(want)
+
+	for _, limit := range []string{"1", "2", "5"} {
+		schemes := listAll[models.Scheme](api, "/api/schemes?sort=name&limit="+limit)
+		var names []string
+		ids := make(map[uuid.UUID]bool)
+		for _, s := range schemes {
+			names = append(names, s.Name)
+			ids[s.ID] = true
+		}
+		if !slices.Equal(names, want) || len(ids) != len(want) {
+			t.Errorf("limit=%s listed %q with %d distinct IDs, want %q", limit, names, len(ids), want)
+		}
+	}
+
+	var first list[models.Scheme]
+	decode(t, api.do("GET", "/api/schemes?limit=5", ""), &first)
+	if len(first.Data) != 5 || first.NextCursor != "" {
+		t.Errorf("a full last page has %d items and cursor %q, want 5 and none", len(first.Data), first.NextCursor)
+	}
+}
+
+func TestListRejectsBadPages(t *testing.T) {
+	api := newTestAPI(t)
+	for _, name := range []string{"Alpha", "Beta"} {
+		var scheme models.Scheme
+		api.create("/api/schemes", `{"name": "`+name+`"}`, &scheme)
+	}
+	var page list[models.Scheme]
+	decode(t, api.do("GET", "/api/schemes?sort=name&limit=1", ""), &page)
+	cursor := url.QueryEscape(page.NextCursor)
+	tampered := url.QueryEscape(strings.ToUpper(page.NextCursor[:len(page.NextCursor)-2]) + "xx")
+
+	for _, path := range []string{
+		"/api/schemes?sort=name&cursor=" + tampered,
+		"/api/schemes?sort=name&cursor=not-a-cursor",
+		"/api/schemes?sort=-name&cursor=" + cursor,
+		"/api/schemes?sort=id&cursor=" + cursor,
+		"/api/applications?cursor=" + cursor,
+		"/api/applicants?cursor=" + cursor,
+		"/api/schemes?sort=amount",
+		"/api/applications?sort=-status",
+		"/api/applicants?sort=income",
+		"/api/schemes?limit=ten",
+		"/api/schemes?limit=-1",
+	} {
+		t.Run(path, func(t *testing.T) {
+			expectProblem(t, api.do("GET", path, ""), http.StatusBadRequest, "invalid_page")
+		})
+	}
+}
+
+func TestListApplicationFilters(t *testing.T) {
+	api := newTestAPI(t)
+	first := newApplication(api)
+	second := newApplication(api)
+	rec := api.transition(second, "submitted", `"1"`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("submit = %d %s", rec.Code, rec.Body)
+	}
+
+	tests := []struct {
+		query string
+		want  []uuid.UUID
+	}{
+		{"", []uuid.UUID{first.ID, second.ID}},
+		{"status=draft", []uuid.UUID{first.ID}},
+		{"status=submitted", []uuid.UUID{second.ID}},
+		{"status=approved", nil},
+		{"scheme_id=" + second.SchemeID.String(), []uuid.UUID{second.ID}},
+		{"applicant_id=" + first.ApplicantID.String(), []uuid.UUID{first.ID}},
+		{"applicant_id=" + first.ApplicantID.String() + "&scheme_id=" + second.SchemeID.String(), nil},
+		{"applicant_id=" + uuid.NewString(), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var got []uuid.UUID
+			for _, a := range listAll[models.Application](api, "/api/applications?sort=id&"+tt.query) {
+				got = append(got, a.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("listed %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, query := range []string{"status=pending", "scheme_id=42", "applicant_id=mary"} {
+		expectProblem(t, api.do("GET", "/api/applications?"+query, ""), http.StatusBadRequest, "invalid_parameter")
+	}
+}
+
+func TestListApplicantFilters(t *testing.T) {
+	api := newTestAPI(t)
+	bodies := map[string]string{
+		"unemployed married": applicantBody,
+		"employed married":   strings.Replace(applicantBody, `"unemployed"`, `"employed"`, 1),
+		"employed single": strings.Replace(strings.Replace(applicantBody, `"unemployed"`, `"employed"`, 1),
+			`"married"`, `"single"`, 1),
+	}
+	ids := make(map[string]uuid.UUID)
+	for _, kind := range []string{"unemployed married", "employed married", "employed single"} {
+		var applicant models.Applicant
+		api.create("/api/applicants", bodies[kind], &applicant)
+		ids[kind] = applicant.ID
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"employment_status=employed", []string{"employed married", "employed single"}},
+		{"employment_status=Unemployed", []string{"unemployed married"}},
+		{"marital_status=married", []string{"unemployed married", "employed married"}},
+		{"employment_status=employed&marital_status=single", []string{"employed single"}},
+		{"employment_status=retired", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var want []uuid.UUID
+			for _, kind := range tt.want {
+				want = append(want, ids[kind])
+			}
+			var got []uuid.UUID
+			for _, a := range listAll[models.Applicant](api, "/api/applicants?limit=1&"+tt.query) {
+				got = append(got, a.ID)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("listed %v, want %v", got, want)
+			}
+		})
+	}
+
+	for _, query := range []string{"employment_status=idle", "marital_status=engaged"} {
+		expectProblem(t, api.do("GET", "/api/applicants?"+query, ""), http.StatusBadRequest, "invalid_parameter")
+	}
+}
+
+func TestListApplicantsRejectsEncryptedSorts(t *testing.T) {
+	api := newTestAPI(t)
+	for _, sort := range []string{"name", "-name", "date_of_birth", "-date_of_birth"} {
+		p := expectProblem(t, api.do("GET", "/api/applicants?sort="+sort, ""), http.StatusBadRequest, "unsupported_sort")
+		if !strings.Contains(p.Detail, "encrypted") {
+			t.Errorf("sort=%s: detail %q does not say why", sort, p.Detail)
+		}
+	}
+	if rec := api.do("GET", "/api/applicants?sort=-id", ""); rec.Code != http.StatusOK {
+		t.Errorf("sort=-id = %d %s, want 200", rec.Code, rec.Body)
+	}
+}
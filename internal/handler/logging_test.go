@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"bytes"
+	"financial_assistance/internal/config"
+	"financial_assistance/internal/logging"
+	"financial_assistance/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestLogOmitsPersonalData(t *testing.T) {
+	api := newTestAPI(t)
+	var buf bytes.Buffer
+	handler := LogRequests(logging.New(&buf, config.LogConfig{Level: "debug", Format: "json"}))(api.router)
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	body := `{
+		"name": "Mary Tan",
+		"national_id": "S8012345A",
+		"employment_status": "unemployed",
+		"marital_status": "married",
+		"sex": "female",
+		"date_of_birth": "1985-03-14",
+		"monthly_income": 1234.5
+	}`
+	rec := serve("POST", "/api/applicants", body)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST = %d %s", rec.Code, rec.Body)
+	}
+	var created models.Applicant
+	decode(t, rec, &created)
+
+	serve("GET", "/api/applicants?national_id=S8012345A", "")
+	serve("GET", "/api/applicants?name=Mary+Tan&date_of_birth=1985-03-14", "")
+	serve("PATCH", "/api/applicants/"+created.ID.String(), `{"name": "Mary Tan-Lim", "monthly_income": 1234.5}`)
+	serve("POST", "/api/applicants", strings.Replace(body, `"sex": "female"`, `"sex": "Mary Tan"`, 1))
+
+	output := buf.String()
+	if got := strings.Count(output, `"msg":"request"`); got != 5 {
+		t.Errorf("%d requests logged, want 5:\n%s", got, output)
+	}
+	for _, value := range []string{"Mary", "Tan", "S8012345A", "1985-03-14", "1234.5"} {
+		if strings.Contains(output, value) {
+			t.Errorf("request log contains %q:\n%s", value, output)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"financial_assistance/internal/models"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestDeleteScheme(t *testing.T) {
+	api := newTestAPI(t)
+	application := newApplication(api)
+	path := "/api/schemes/" + application.SchemeID.String()
+
+	expectProblem(t, api.do("DELETE", path, "", "If-Match", `"1"`), http.StatusConflict, "scheme_in_use")
+	if rec := api.do("GET", path, ""); rec.Code != http.StatusOK {
+		t.Fatalf("GET after a refused delete = %d, want 200", rec.Code)
+	}
+
+	var unused models.Scheme
+	api.create("/api/schemes", `{"name": "Unused Grant"}`, &unused)
+	unusedPath := "/api/schemes/" + unused.ID.String()
+	expectProblem(t, api.do("DELETE", unusedPath, "", "If-Match", `"2"`), http.StatusPreconditionFailed, "version_mismatch")
+	if rec := api.do("DELETE", unusedPath, "", "If-Match", `"1"`); rec.Code != http.StatusNoContent {
+		t.Fatalf("DELETE = %d %s, want 204", rec.Code, rec.Body)
+	}
+	expectProblem(t, api.do("GET", unusedPath, ""), http.StatusNotFound, "scheme_not_found")
+	expectProblem(t, api.do("DELETE", unusedPath, "", "If-Match", `"1"`), http.StatusNotFound, "scheme_not_found")
+}
+
+func TestSchemeBenefits(t *testing.T) {
+	api := newTestAPI(t)
+	var scheme models.Scheme
+	api.create("/api/schemes", `{"name": "Universal Grant"}`, &scheme)
+	path := "/api/schemes/" + scheme.ID.String()
+
+	rec := api.do("POST", path+"/benefits", `{"name": "Cash", "amount": 500}`, "If-Match", `"1"`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("add benefit = %d %s, want 201", rec.Code, rec.Body)
+	}
+	var benefit models.Benefit
+	decode(t, rec, &benefit)
+	benefitPath := path + "/benefits/" + benefit.ID.String()
+	if got := rec.Header().Get("ETag"); got != `"2"` {
+		t.Errorf("add benefit ETag = %q, want \"2\"", got)
+	}
+	if got := rec.Header().Get("Location"); got != benefitPath {
+		t.Errorf("add benefit Location = %q, want %q", got, benefitPath)
+	}
+
+	// The benefit shares the version of its scheme.
+	rec = api.do("GET", benefitPath, "")
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"2"` {
+		t.Errorf("GET benefit = %d with ETag %q, want 200 with \"2\"", rec.Code, rec.Header().Get("ETag"))
+	}
+	decode(t, api.do("GET", path, ""), &scheme)
+	if scheme.Version != 2 || len(scheme.Benefits) != 1 || scheme.Benefits[0].ID != benefit.ID {
+		t.Errorf("scheme at version %d with benefits %+v, want version 2 with %s", scheme.Version, scheme.Benefits, benefit.ID)
+	}
+
+	expectProblem(t, api.do("DELETE", benefitPath, "", "If-Match", `"1"`), http.StatusPreconditionFailed, "version_mismatch")
+	rec = api.do("DELETE", benefitPath, "", "If-Match", `"2"`)
+	if rec.Code != http.StatusNoContent || rec.Header().Get("ETag") != `"3"` {
+		t.Fatalf("delete benefit = %d with ETag %q, want 204 with \"3\"", rec.Code, rec.Header().Get("ETag"))
+	}
+	var stored models.Scheme
+	decode(t, api.do("GET", path, ""), &stored)
+	if stored.Version != 3 || len(stored.Benefits) != 0 {
+		t.Errorf("scheme at version %d with benefits %+v, want version 3 without any", stored.Version, stored.Benefits)
+	}
+
+	expectProblem(t, api.do("GET", benefitPath, ""), http.StatusNotFound, "benefit_not_found")
+	expectProblem(t, api.do("DELETE", benefitPath, "", "If-Match", `"3"`), http.StatusNotFound, "benefit_not_found")
+	unknown := path + "/benefits/" + uuid.NewString()
+	expectProblem(t, api.do("DELETE", unknown, "", "If-Match", `"3"`), http.StatusNotFound, "benefit_not_found")
+	unknownScheme := "/api/schemes/" + uuid.NewString() + "/benefits/" + benefit.ID.String()
+	expectProblem(t, api.do("DELETE", unknownScheme, "", "If-Match", `"1"`), http.StatusNotFound, "scheme_not_found")
+
+	// Failed deletes leave the scheme as it was.
+	decode(t, api.do("GET", path, ""), &stored)
+	if stored.Version != 3 {
+		t.Errorf("scheme at version %d after failed deletes, want 3", stored.Version)
+	}
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"financial_assistance/internal/models"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCreateRejectsInvalidFields(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		body string
+		want []string
+	}{
+		{
+			name: "applicant",
+			path: "/api/applicants",
+			body: `{
+				"name": "",
+				"employment_status": "on_holiday",
+				"marital_status": "married",
+				"sex": "robot",
+				"date_of_birth": "2999-01-01",
+				"monthly_income": -1,
+				"household": [{"name": "Gwen Tan", "sex": "female", "date_of_birth": "2016-02-01", "relation": "cousin"}]
+			}`,
+			want: []string{
+				"name: required",
+				"employment_status: invalid_value",
+				"sex: invalid_value",
+				"date_of_birth: future_date",
+				"monthly_income: negative",
+				"household[0].relation: invalid_value",
+			},
+		},
+		{
+			name: "scheme",
+			path: "/api/schemes",
+			body: `{
+				"name": "Retrenchment Assistance",
+				"criteria": {"marital_status": "engaged", "min_applicant_age": 65, "max_applicant_age": 21},
+				"benefits": [{"name": "", "amount": 100}]
+			}`,
+			want: []string{
+				"criteria.marital_status: invalid_value",
+				"criteria.min_applicant_age: invalid_range",
+				"benefits[0].name: required",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestAPI(t)
+			p := expectProblem(t, api.do("POST", tt.path, tt.body), http.StatusBadRequest, "validation_failed")
+			got := p.fields()
+			for _, field := range tt.want {
+				if !slices.Contains(got, field) {
+					t.Errorf("field errors %v lack %q", got, field)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateApplicationForUnknownApplicant(t *testing.T) {
+	api := newTestAPI(t)
+	var scheme models.Scheme
+	api.create("/api/schemes", `{"name": "Universal Grant"}`, &scheme)
+
+	body := `{"applicant_id": "0190b2a4-0000-7000-8000-000000000000", "scheme_id": "` + scheme.ID.String() + `"}`
+	expectProblem(t, api.do("POST", "/api/applications", body), http.StatusUnprocessableEntity, "unknown_applicant")
+}
+
+func TestCreateApplicationWhenIneligible(t *testing.T) {
+	api := newTestAPI(t)
+	var applicant models.Applicant
+	api.create("/api/applicants", applicantBody, &applicant)
+	var scheme models.Scheme
+	api.create("/api/schemes", `{"name": "Retrenchment Assistance", "criteria": {"employment_status": "employed", "has_children": true}}`, &scheme)
+
+	body := `{"applicant_id": "` + applicant.ID.String() + `", "scheme_id": "` + scheme.ID.String() + `"}`
+	p := expectProblem(t, api.do("POST", "/api/applications", body), http.StatusUnprocessableEntity, "not_eligible")
+	if !slices.Equal(p.FailedCriteria, []string{"employment_status"}) {
+		t.Errorf("failed_criteria = %v, want [employment_status]", p.FailedCriteria)
+	}
+}
+
+func TestCreateRejectsMalformedBody(t *testing.T) {
+	api := newTestAPI(t)
+	for _, body := range []string{`{"name": `, `{"date_of_birth": "14/03/1985"}`, `{"monthly_income": "a lot"}`} {
+		rec := api.do("POST", "/api/applicants", body)
+		p := expectProblem(t, rec, http.StatusBadRequest, "invalid_body")
+		if !strings.HasPrefix(p.Detail, "invalid request body") {
+			t.Errorf("detail = %q for %s", p.Detail, body)
+		}
+	}
+}
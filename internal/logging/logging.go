@@ -0,0 +1,62 @@
+// Package logging configures the structured logger and carries
+// request-scoped loggers through contexts.
+package logging
+
+import (
+	"context"
+	"financial_assistance/internal/config"
+	"io"
+	"log/slog"
+)
+
+// redactedKeys are attribute keys whose values are never written, whatever
+// logged them. Records with personal data implement slog.LogValuer as well;
+// this is the backstop for plain values.
+var redactedKeys = map[string]bool{
+	"name":           true,
+	"date_of_birth":  true,
+	"national_id":    true,
+	"income":         true,
+	"monthly_income": true,
+	"password":       true,
+	"token":          true,
+	"access_token":   true,
+	"api_key":        true,
+	"authorization":  true,
+}
+
+const redacted = "[REDACTED]"
+
+// New returns a logger writing to w at the configured level and format.
+func New(w io.Writer, cfg config.LogConfig) *slog.Logger {
+	opts := &slog.HandlerOptions{
+		Level:       cfg.SlogLevel(),
+		ReplaceAttr: redact,
+	}
+	if cfg.Format == "json" {
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+	return slog.New(slog.NewTextHandler(w, opts))
+}
+
+func redact(groups []string, a slog.Attr) slog.Attr {
+	if redactedKeys[a.Key] {
+		return slog.String(a.Key, redacted)
+	}
+	return a
+}
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger stored by NewContext, or the default
+// logger outside of a request.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"bytes"
+	"financial_assistance/internal/config"
+	"financial_assistance/internal/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// personalData are values that must never appear in a log line.
+var personalData = []string{"Mary", "Tan", "S8012345A", "1985-03-14", "1234.5", "2016-02-01", "Gwen"}
+
+func applicant() models.Applicant {
+	id := uuid.New()
+	return models.Applicant{
+		ID:            id,
+		Name:          "Mary Tan",
+		NationalID:    "S8012345A",
+		DateOfBirth:   time.Date(1985, time.March, 14, 0, 0, 0, 0, time.UTC),
+		MonthlyIncome: 1234.5,
+		HouseholdMembers: []models.HouseholdMember{{
+			ID:            uuid.New(),
+			ApplicantID:   id,
+			Name:          "Gwen Tan",
+			DateOfBirth:   time.Date(2016, time.February, 1, 0, 0, 0, 0, time.UTC),
+			MonthlyIncome: 1234.5,
+		}},
+		Version: 3,
+	}
+}
+
+func expectNoPersonalData(t *testing.T, output string) {
+	t.Helper()
+	for _, value := range personalData {
+		if strings.Contains(output, value) {
+			t.Errorf("log contains %q:\n%s", value, output)
+		}
+	}
+}
+
+func TestRecordsLogOnlyIdentifiers(t *testing.T) {
+	a := applicant()
+	for _, format := range []string{"text", "json"} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := New(&buf, config.LogConfig{Level: "debug", Format: format})
+			logger.Info("applicant", "applicant", a, "pointer", &a)
+			logger.Info("member", "member", a.HouseholdMembers[0])
+
+			output := buf.String()
+			expectNoPersonalData(t, output)
+			for _, want := range []string{a.ID.String(), a.HouseholdMembers[0].ID.String(), "household_size"} {
+				if !strings.Contains(output, want) {
+					t.Errorf("log lacks %q:\n%s", want, output)
+				}
+			}
+		})
+	}
+}
+
+func TestPersonalDataKeysAreRedacted(t *testing.T) {
+	for _, format := range []string{"text", "json"} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := New(&buf, config.LogConfig{Level: "debug", Format: format})
+			logger.Info("lookup",
+				"name", "Mary Tan",
+				"national_id", "S8012345A",
+				"date_of_birth", "1985-03-14",
+				"monthly_income", 1234.5,
+				"income", 1234.5,
+			)
+
+			output := buf.String()
+			expectNoPersonalData(t, output)
+			if got := strings.Count(output, redacted); got != 5 {
+				t.Errorf("%d values redacted, want 5:\n%s", got, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package migrations
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func file(body string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(body)} }
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0010_add_index.up.sql":      file("CREATE INDEX"),
+		"0010_add_index.down.sql":    file("DROP INDEX"),
+		"0002_create_table.up.sql":   file("CREATE TABLE"),
+		"0002_create_table.down.sql": file("DROP TABLE"),
+		"0003_v2_columns.up.sql":     file("ALTER TABLE"),
+		"0003_v2_columns.down.sql":   file("ALTER TABLE back"),
+		"notes/0004_ignored.up.sql":  file("ignored, since it is in a directory"),
+	}
+
+	migrations, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := []Migration{
+		{Version: 2, Name: "create_table", Up: "CREATE TABLE", Down: "DROP TABLE"},
+		{Version: 3, Name: "v2_columns", Up: "ALTER TABLE", Down: "ALTER TABLE back"},
+		{Version: 10, Name: "add_index", Up: "CREATE INDEX", Down: "DROP INDEX"},
+	}
+	if len(migrations) != len(want) {
+		t.Fatalf("Load = %+v, want %+v", migrations, want)
+	}
+	for i := range want {
+		if migrations[i] != want[i] {
+			t.Errorf("migration %d = %+v, want %+v", i, migrations[i], want[i])
+		}
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		fsys  fstest.MapFS
+		error string
+	}{
+		{"missing down", fstest.MapFS{
+			"0001_init.up.sql": file("CREATE"),
+		}, "needs both an up and a down file"},
+		{"missing up", fstest.MapFS{
+			"0001_init.down.sql": file("DROP"),
+		}, "needs both an up and a down file"},
+		{"empty up", fstest.MapFS{
+			"0001_init.up.sql":   file(""),
+			"0001_init.down.sql": file("DROP"),
+		}, "needs both an up and a down file"},
+		{"duplicate version", fstest.MapFS{
+			"0001_init.up.sql":    file("CREATE"),
+			"0001_init.down.sql":  file("DROP"),
+			"0001_other.up.sql":   file("CREATE"),
+			"0001_other.down.sql": file("DROP"),
+		}, "has two names"},
+		{"same version written differently", fstest.MapFS{
+			"0001_init.up.sql": file("CREATE"),
+			"1_init.down.sql":  file("DROP"),
+			"01_other.up.sql":  file("CREATE"),
+		}, "has two names"},
+		{"version zero", fstest.MapFS{
+			"0000_init.up.sql":   file("CREATE"),
+			"0000_init.down.sql": file("DROP"),
+		}, "invalid version"},
+		{"version out of range", fstest.MapFS{
+			"99999999999999999999_init.up.sql": file("CREATE"),
+		}, "invalid version"},
+		{"no version", fstest.MapFS{
+			"init.up.sql": file("CREATE"),
+		}, "name must look like"},
+		{"no direction", fstest.MapFS{
+			"0001_init.sql": file("CREATE"),
+		}, "name must look like"},
+		{"upper case description", fstest.MapFS{
+			"0001_Init.up.sql": file("CREATE"),
+		}, "name must look like"},
+		{"stray file", fstest.MapFS{
+			"0001_init.up.sql":   file("CREATE"),
+			"0001_init.down.sql": file("DROP"),
+			"README.md":          file("notes"),
+		}, "name must look like"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := Load(tt.fsys)
+			if err == nil || !strings.Contains(err.Error(), tt.error) {
+				t.Errorf("Load = %+v, %v; want an error containing %q", migrations, err, tt.error)
+			}
+		})
+	}
+}
+
+// TestEmbedded checks the migrations shipped in the binary: every one has
+// both an up and a down file, and versions run from 1 without gaps.
+func TestEmbedded(t *testing.T) {
+	migrations, err := All()
+	if err != nil {
+		t.Fatalf("All: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	for i, migration := range migrations {
+		for _, direction := range []string{"up", "down"} {
+			name := fmt.Sprintf("sql/%04d_%s.%s.sql", migration.Version, migration.Name, direction)
+			if _, err := fs.Stat(files, name); err != nil {
+				t.Errorf("migration %d_%s has no %s file: %v", migration.Version, migration.Name, direction, err)
+			}
+		}
+		if migration.Version != int64(i+1) {
+			t.Errorf("migration %d_%s follows version %d", migration.Version, migration.Name, i)
+		}
+		if strings.TrimSpace(migration.Up) == "" || strings.TrimSpace(migration.Down) == "" {
+			t.Errorf("migration %d_%s has an empty up or down file", migration.Version, migration.Name)
+		}
+	}
+}
+
+func TestStatus(t *testing.T) {
+	migrations := []Migration{{Version: 1, Name: "init"}, {Version: 2, Name: "roles"}, {Version: 3, Name: "etag"}}
+	at := time.Date(2026, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	statuses := statusOf(migrations, map[int64]time.Time{1: at, 3: at.Add(time.Hour)})
+	want := []struct {
+		version   int64
+		name      string
+		appliedAt *time.Time
+	}{
+		{1, "init", &at},
+		{2, "roles", nil},
+		{3, "etag", ptr(at.Add(time.Hour))},
+	}
+	if len(statuses) != len(want) {
+		t.Fatalf("statusOf = %+v, want %d statuses", statuses, len(want))
+	}
+	for i, w := range want {
+		s := statuses[i]
+		applied := s.AppliedAt != nil && w.appliedAt != nil && s.AppliedAt.Equal(*w.appliedAt) ||
+			s.AppliedAt == nil && w.appliedAt == nil
+		if s.Version != w.version || s.Name != w.name || !applied {
+			t.Errorf("status %d = %d_%s applied at %v, want %d_%s applied at %v",
+				i, s.Version, s.Name, s.AppliedAt, w.version, w.name, w.appliedAt)
+		}
+	}
+
+	if err := checkKnown(migrations, map[int64]time.Time{1: at, 2: at}); err != nil {
+		t.Errorf("checkKnown with known versions applied: %v", err)
+	}
+	if err := checkKnown(migrations, map[int64]time.Time{1: at, 4: at}); err == nil {
+		t.Error("checkKnown accepted a database migrated by a newer binary")
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS application_events;
+DROP TABLE IF EXISTS applications;
+DROP TABLE IF EXISTS benefits;
+DROP TABLE IF EXISTS criteria;
+DROP TABLE IF EXISTS schemes;
+DROP TABLE IF EXISTS household_members;
+DROP TABLE IF EXISTS applicants;
@@ -0,0 +1,94 @@
+CREATE TABLE applicants (
+    id UUID PRIMARY KEY ,
+    name VARCHAR(255),
+    employment_status VARCHAR(50),
+    marital_status VARCHAR(50),
+    sex VARCHAR(10),
+    date_of_birth DATE,
+    monthly_income NUMERIC(12,2) NOT NULL DEFAULT 0
+);
+
+CREATE TABLE household_members (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    employment_status VARCHAR(50),
+    sex VARCHAR(10),
+    date_of_birth DATE,
+    relation VARCHAR(50),
+    school_level VARCHAR(50),
+    monthly_income NUMERIC(12,2) NOT NULL DEFAULT 0,
+    applicant_id UUID,
+    FOREIGN KEY (applicant_id) REFERENCES applicants(id)
+);
+
+CREATE TABLE schemes (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255),
+    allow_reapply_after_rejection BOOLEAN NOT NULL DEFAULT false,
+    reapply_cooldown_days INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE criteria (
+    scheme_id UUID,
+    employment_status VARCHAR(50),
+    marital_status VARCHAR(50),
+    has_children BOOLEAN,
+    min_applicant_age INTEGER,
+    max_applicant_age INTEGER,
+    household_member_min_age INTEGER,
+    household_member_max_age INTEGER,
+    max_household_income NUMERIC(12,2),
+    max_per_capita_income NUMERIC(12,2),
+    FOREIGN KEY (scheme_id) REFERENCES schemes(id)
+);
+
+CREATE TABLE benefits (
+    id UUID PRIMARY KEY,
+    scheme_id UUID,
+    name VARCHAR(255) NOT NULL,
+    amount NUMERIC(10,2),
+    FOREIGN KEY (scheme_id) REFERENCES schemes(id)
+);
+
+CREATE TABLE applications (
+    application_id UUID PRIMARY KEY,
+    applicant_id UUID,
+    scheme_id UUID,
+    status VARCHAR(50) NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'submitted', 'under_review', 'approved', 'rejected', 'withdrawn', 'disbursed')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FOREIGN KEY (applicant_id) REFERENCES applicants(id),
+    FOREIGN KEY (scheme_id) REFERENCES schemes(id)
+);
+
+-- At most one open (non-terminal) application per applicant and scheme.
+CREATE UNIQUE INDEX applications_active_applicant_scheme_key
+    ON applications (applicant_id, scheme_id)
+    WHERE status NOT IN ('rejected', 'withdrawn', 'disbursed');
+
+CREATE TABLE application_events (
+    id UUID PRIMARY KEY,
+    application_id UUID NOT NULL,
+    from_status VARCHAR(50),
+    to_status VARCHAR(50) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    reason_code VARCHAR(100),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FOREIGN KEY (application_id) REFERENCES applications(application_id)
+);
+
+CREATE INDEX application_events_application_id_idx ON application_events (application_id, created_at);
+
+-- Batch loading of household members and benefits.
+CREATE INDEX household_members_applicant_id_idx ON household_members (applicant_id);
+CREATE INDEX benefits_scheme_id_idx ON benefits (scheme_id);
+
+-- Keyset pagination and list filters.
+CREATE INDEX applicants_name_id_idx ON applicants (name, id);
+CREATE INDEX applicants_date_of_birth_id_idx ON applicants (date_of_birth, id);
+CREATE INDEX schemes_name_id_idx ON schemes (name, id);
+CREATE INDEX applications_created_at_id_idx ON applications (created_at, application_id);
+CREATE INDEX applications_updated_at_id_idx ON applications (updated_at, application_id);
+CREATE INDEX applications_status_idx ON applications (status);
+CREATE INDEX applications_scheme_id_idx ON applications (scheme_id);
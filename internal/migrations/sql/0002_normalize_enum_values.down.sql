@@ -0,0 +1,3 @@
+-- The original spelling of normalized values is not kept, so there is
+-- nothing to undo.
+SELECT 1;
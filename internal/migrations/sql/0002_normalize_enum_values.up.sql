@@ -0,0 +1,20 @@
+-- Bring free-text values written before enumerations were enforced into
+-- their canonical form: lower case with underscores.
+CREATE FUNCTION pg_temp.normalize_enum(value TEXT) RETURNS TEXT AS $$
+    SELECT translate(lower(trim(value)), ' -', '__')
+$$ LANGUAGE SQL IMMUTABLE;
+
+UPDATE applicants SET
+    employment_status = pg_temp.normalize_enum(employment_status),
+    marital_status = pg_temp.normalize_enum(marital_status),
+    sex = pg_temp.normalize_enum(sex);
+
+UPDATE household_members SET
+    employment_status = pg_temp.normalize_enum(employment_status),
+    sex = pg_temp.normalize_enum(sex),
+    relation = pg_temp.normalize_enum(relation),
+    school_level = pg_temp.normalize_enum(school_level);
+
+UPDATE criteria SET
+    employment_status = NULLIF(pg_temp.normalize_enum(employment_status), ''),
+    marital_status = NULLIF(pg_temp.normalize_enum(marital_status), '');
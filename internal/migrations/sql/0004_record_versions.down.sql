@@ -0,0 +1,3 @@
+ALTER TABLE applications DROP COLUMN IF EXISTS version;
+ALTER TABLE schemes DROP COLUMN IF EXISTS version;
+ALTER TABLE applicants DROP COLUMN IF EXISTS version;
@@ -0,0 +1,4 @@
+-- Row versions for optimistic concurrency control; served as ETags.
+ALTER TABLE applicants ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE schemes ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE applications ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
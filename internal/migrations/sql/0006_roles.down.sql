@@ -0,0 +1,2 @@
+ALTER TABLE api_keys DROP COLUMN role;
+ALTER TABLE users DROP COLUMN role;
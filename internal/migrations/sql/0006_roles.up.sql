@@ -0,0 +1,9 @@
+-- Accounts created before roles existed had full access and keep it until
+-- an administrator assigns them a narrower role.
+ALTER TABLE users ADD COLUMN role VARCHAR(32) NOT NULL DEFAULT 'admin'
+    CONSTRAINT users_role_check CHECK (role IN ('admin', 'caseworker', 'auditor'));
+ALTER TABLE users ALTER COLUMN role DROP DEFAULT;
+
+ALTER TABLE api_keys ADD COLUMN role VARCHAR(32) NOT NULL DEFAULT 'admin'
+    CONSTRAINT api_keys_role_check CHECK (role IN ('admin', 'caseworker', 'auditor'));
+ALTER TABLE api_keys ALTER COLUMN role DROP DEFAULT;
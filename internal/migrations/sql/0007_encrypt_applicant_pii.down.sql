@@ -0,0 +1,30 @@
+-- Sealed values cannot be decrypted here, so refuse to drop them.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM applicants WHERE key_id IS NOT NULL)
+        OR EXISTS (SELECT 1 FROM household_members WHERE key_id IS NOT NULL) THEN
+        RAISE EXCEPTION 'applicants hold encrypted data; reverting would lose it';
+    END IF;
+END
+$$;
+
+CREATE INDEX applicants_name_id_idx ON applicants (name, id);
+CREATE INDEX applicants_date_of_birth_id_idx ON applicants (date_of_birth, id);
+
+DROP INDEX applicants_key_id_idx;
+DROP INDEX applicants_name_dob_index_idx;
+DROP INDEX applicants_national_id_index_idx;
+
+ALTER TABLE household_members
+    DROP COLUMN key_id,
+    DROP COLUMN encrypted_date_of_birth,
+    DROP COLUMN encrypted_name,
+    ALTER COLUMN name SET NOT NULL;
+
+ALTER TABLE applicants
+    DROP COLUMN key_id,
+    DROP COLUMN name_dob_index,
+    DROP COLUMN national_id_index,
+    DROP COLUMN encrypted_national_id,
+    DROP COLUMN encrypted_date_of_birth,
+    DROP COLUMN encrypted_name;
@@ -0,0 +1,27 @@
+-- Names, dates of birth and national IDs are stored sealed with envelope
+-- encryption; key_id names the keyring key they were sealed with. Rows
+-- written before keep their plaintext columns, with key_id NULL, until
+-- "encryption rotate" seals them and clears the plaintext.
+ALTER TABLE applicants
+    ADD COLUMN encrypted_name BYTEA,
+    ADD COLUMN encrypted_date_of_birth BYTEA,
+    ADD COLUMN encrypted_national_id BYTEA,
+    ADD COLUMN national_id_index BYTEA,
+    ADD COLUMN name_dob_index BYTEA,
+    ADD COLUMN key_id VARCHAR(255);
+
+ALTER TABLE household_members
+    ALTER COLUMN name DROP NOT NULL,
+    ADD COLUMN encrypted_name BYTEA,
+    ADD COLUMN encrypted_date_of_birth BYTEA,
+    ADD COLUMN key_id VARCHAR(255);
+
+-- Blind indexes: keyed hashes that support exact-match lookups.
+CREATE INDEX applicants_national_id_index_idx ON applicants (national_id_index);
+CREATE INDEX applicants_name_dob_index_idx ON applicants (name_dob_index);
+CREATE INDEX applicants_key_id_idx ON applicants (key_id);
+
+-- Sealed values have no order, so applicants are listed by ID only and the
+-- indexes for sorting by name and date of birth are no longer used.
+DROP INDEX applicants_name_id_idx;
+DROP INDEX applicants_date_of_birth_id_idx;
@@ -0,0 +1,2 @@
+DROP INDEX schemes_name_id_idx;
+CREATE INDEX schemes_name_id_idx ON schemes (name, id);
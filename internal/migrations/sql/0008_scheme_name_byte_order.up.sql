@@ -0,0 +1,4 @@
+-- Schemes are listed by name in byte order, whatever the database collation,
+-- so the index must use the same order to serve those queries.
+DROP INDEX schemes_name_id_idx;
+CREATE INDEX schemes_name_id_idx ON schemes (name COLLATE "C", id);
@@ -0,0 +1,3 @@
+-- Replayed create responses carry the ETag of the created record, which the
+-- client needs for its next If-Match.
+ALTER TABLE idempotency_keys ADD COLUMN etag VARCHAR(255);
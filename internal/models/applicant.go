@@ -3,7 +3,10 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/google/uuid"
 )
@@ -11,24 +14,105 @@ import (
 type Applicant struct {
 	ID               uuid.UUID         `json:"id" db:"id"`
 	Name             string            `json:"name" db:"name"`
-	EmploymentStatus string            `json:"employment_status" db:"employment_status"`
-	MaritalStatus    string            `json:"marital_status" db:"marital_status"`
-	Sex              string            `json:"sex" db:"sex"`
+	NationalID       string            `json:"national_id,omitempty" db:"national_id"`
+	EmploymentStatus EmploymentStatus  `json:"employment_status" db:"employment_status"`
+	MaritalStatus    MaritalStatus     `json:"marital_status" db:"marital_status"`
+	Sex              Sex               `json:"sex" db:"sex"`
 	DateOfBirth      time.Time         `json:"date_of_birth" db:"date_of_birth"`
+	MonthlyIncome    float64           `json:"monthly_income" db:"monthly_income"`
 	HouseholdMembers []HouseholdMember `json:"household,omitempty"`
+
+	// Version counts the changes made to the applicant, including changes to
+	// its household. It is served as the ETag.
+	Version int `json:"version" db:"version"`
 }
 
 type HouseholdMember struct {
-	ID               uuid.UUID `json:"id" db:"id"`
-	Name             string    `json:"name" db:"name"`
-	EmploymentStatus string    `json:"employment_status" db:"employment_status"`
-	Sex              string    `json:"sex" db:"sex"`
-	DateOfBirth      time.Time `json:"date_of_birth" db:"date_of_birth"`
-	Relation         string    `json:"relation" db:"relation"`
-	SchoolLevel      string    `json:"school_level" db:"school_level"`
-	ApplicantID      uuid.UUID `json:"applicant_id" db:"applicant_id"`
+	ID               uuid.UUID        `json:"id" db:"id"`
+	Name             string           `json:"name" db:"name"`
+	EmploymentStatus EmploymentStatus `json:"employment_status" db:"employment_status"`
+	Sex              Sex              `json:"sex" db:"sex"`
+	DateOfBirth      time.Time        `json:"date_of_birth" db:"date_of_birth"`
+	Relation         Relation         `json:"relation" db:"relation"`
+	SchoolLevel      SchoolLevel      `json:"school_level" db:"school_level"`
+	MonthlyIncome    float64          `json:"monthly_income" db:"monthly_income"`
+	ApplicantID      uuid.UUID        `json:"applicant_id" db:"applicant_id"`
+}
+
+// MaxNationalIDLength bounds national IDs after normalization.
+const MaxNationalIDLength = 32
+
+// NormalizeNationalID upper-cases a national ID and drops the spaces and
+// hyphens it is often written with, so "s1234567-d" matches "S1234567D".
+func NormalizeNationalID(id string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '-' {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, id)
+}
+
+// NormalizeName lower-cases a name and collapses its whitespace, for
+// comparing names typed by different people.
+func NormalizeName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// LogValue keeps personal data (names, national IDs, dates of birth,
+// incomes) out of logs; only identifiers are written.
+func (a Applicant) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", a.ID.String()),
+		slog.Int("version", a.Version),
+		slog.Int("household_size", len(a.HouseholdMembers)+1),
+	)
+}
+
+func (m HouseholdMember) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", m.ID.String()),
+		slog.String("applicant_id", m.ApplicantID.String()),
+	)
+}
+
+// AgeAt returns the age in completed years on the given date.
+func AgeAt(dateOfBirth, at time.Time) int {
+	age := at.Year() - dateOfBirth.Year()
+	if at.Month() < dateOfBirth.Month() ||
+		(at.Month() == dateOfBirth.Month() && at.Day() < dateOfBirth.Day()) {
+		age--
+	}
+	return age
+}
+
+func (a *Applicant) AgeAt(at time.Time) int {
+	return AgeAt(a.DateOfBirth, at)
+}
+
+func (m *HouseholdMember) AgeAt(at time.Time) int {
+	return AgeAt(m.DateOfBirth, at)
+}
+
+// HouseholdIncome is the combined monthly income of the applicant and every
+// household member.
+func (a *Applicant) HouseholdIncome() float64 {
+	total := a.MonthlyIncome
+	for _, member := range a.HouseholdMembers {
+		total += member.MonthlyIncome
+	}
+	return total
+}
+
+// PerCapitaIncome divides the household income by the household size, which
+// counts the applicant.
+func (a *Applicant) PerCapitaIncome() float64 {
+	return a.HouseholdIncome() / float64(len(a.HouseholdMembers)+1)
 }
 
+// UnmarshalJSON accepts date_of_birth as either RFC 3339 or YYYY-MM-DD. An
+// absent date_of_birth leaves the current value untouched so that a partial
+// document can be applied over an existing applicant.
 func (a *Applicant) UnmarshalJSON(data []byte) error {
 	type Alias Applicant
 	aux := &struct {
@@ -42,19 +126,50 @@ func (a *Applicant) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	var parsedTime time.Time
-	var err error
+	if aux.DateOfBirth != "" {
+		parsedTime, err := parseDate(aux.DateOfBirth)
+		if err != nil {
+			return err
+		}
+		a.DateOfBirth = parsedTime
+	}
 
-	parsedTime, err = time.Parse(time.RFC3339, aux.DateOfBirth)
-	if err != nil {
-		parsedTime, err = time.Parse("2006-01-02", aux.DateOfBirth)
+	return nil
+}
+
+func (m *HouseholdMember) UnmarshalJSON(data []byte) error {
+	type Alias HouseholdMember
+	aux := &struct {
+		DateOfBirth string `json:"date_of_birth"`
+		*Alias
+	}{
+		Alias: (*Alias)(m),
 	}
 
-	if err != nil {
-		return fmt.Errorf("invalid date format: %v", err)
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
 	}
 
-	a.DateOfBirth = parsedTime
+	if aux.DateOfBirth != "" {
+		parsedTime, err := parseDate(aux.DateOfBirth)
+		if err != nil {
+			return err
+		}
+		m.DateOfBirth = parsedTime
+	}
 
 	return nil
 }
+
+func parseDate(value string) (time.Time, error) {
+	parsedTime, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		parsedTime, err = time.Parse("2006-01-02", value)
+	}
+
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date format: %v", err)
+	}
+
+	return parsedTime, nil
+}
@@ -1,12 +1,77 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
+type ApplicationStatus string
+
+const (
+	StatusDraft       ApplicationStatus = "draft"
+	StatusSubmitted   ApplicationStatus = "submitted"
+	StatusUnderReview ApplicationStatus = "under_review"
+	StatusApproved    ApplicationStatus = "approved"
+	StatusRejected    ApplicationStatus = "rejected"
+	StatusWithdrawn   ApplicationStatus = "withdrawn"
+	StatusDisbursed   ApplicationStatus = "disbursed"
+)
+
+// applicationTransitions lists, for each status, the statuses it may move to.
+// Statuses without an entry are terminal.
+var applicationTransitions = map[ApplicationStatus][]ApplicationStatus{
+	StatusDraft:       {StatusSubmitted, StatusWithdrawn},
+	StatusSubmitted:   {StatusUnderReview, StatusWithdrawn},
+	StatusUnderReview: {StatusApproved, StatusRejected, StatusWithdrawn},
+	StatusApproved:    {StatusDisbursed},
+}
+
+func (s ApplicationStatus) Valid() bool {
+	switch s {
+	case StatusDraft, StatusSubmitted, StatusUnderReview, StatusApproved,
+		StatusRejected, StatusWithdrawn, StatusDisbursed:
+		return true
+	}
+	return false
+}
+
+func (s ApplicationStatus) IsTerminal() bool {
+	return s.Valid() && len(applicationTransitions[s]) == 0
+}
+
+// IsInitial reports whether an application may be created in this status.
+func (s ApplicationStatus) IsInitial() bool {
+	return s == StatusDraft || s == StatusSubmitted
+}
+
+func (s ApplicationStatus) CanTransitionTo(next ApplicationStatus) bool {
+	for _, allowed := range applicationTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
 type Application struct {
-	ID          uuid.UUID `json:"application_id" db:"application_id"`
-	ApplicantID uuid.UUID `json:"applicant_id" db:"applicant_id"`
-	SchemeID    uuid.UUID `json:"scheme_id" db:"scheme_id"`
-	Status      string    `json:"status" db:"status"`
+	ID          uuid.UUID         `json:"application_id" db:"application_id"`
+	ApplicantID uuid.UUID         `json:"applicant_id" db:"applicant_id"`
+	SchemeID    uuid.UUID         `json:"scheme_id" db:"scheme_id"`
+	Status      ApplicationStatus `json:"status" db:"status"`
+	CreatedAt   time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at" db:"updated_at"`
+	Version     int               `json:"version" db:"version"`
+}
+
+// ApplicationEvent records a single status change of an application. The
+// event written when an application is created has an empty FromStatus.
+type ApplicationEvent struct {
+	ID            uuid.UUID         `json:"id" db:"id"`
+	ApplicationID uuid.UUID         `json:"application_id" db:"application_id"`
+	FromStatus    ApplicationStatus `json:"from_status,omitempty" db:"from_status"`
+	ToStatus      ApplicationStatus `json:"to_status" db:"to_status"`
+	Actor         string            `json:"actor" db:"actor"`
+	ReasonCode    string            `json:"reason_code,omitempty" db:"reason_code"`
+	CreatedAt     time.Time         `json:"created_at" db:"created_at"`
 }
@@ -0,0 +1,59 @@
+package models
+
+import "testing"
+
+func TestCanTransitionTo(t *testing.T) {
+	statuses := []ApplicationStatus{
+		StatusDraft, StatusSubmitted, StatusUnderReview, StatusApproved,
+		StatusRejected, StatusWithdrawn, StatusDisbursed,
+	}
+	// legal lists every allowed move; all other pairs must be refused.
+	legal := map[ApplicationStatus][]ApplicationStatus{
+		StatusDraft:       {StatusSubmitted, StatusWithdrawn},
+		StatusSubmitted:   {StatusUnderReview, StatusWithdrawn},
+		StatusUnderReview: {StatusApproved, StatusRejected, StatusWithdrawn},
+		StatusApproved:    {StatusDisbursed},
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := false
+			for _, next := range legal[from] {
+				want = want || next == to
+			}
+			if got := from.CanTransitionTo(to); got != want {
+				t.Errorf("%s -> %s allowed = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+
+	for _, s := range []ApplicationStatus{"", "pending", "Draft"} {
+		if StatusDraft.CanTransitionTo(s) || s.CanTransitionTo(StatusSubmitted) {
+			t.Errorf("transition to or from invalid status %q allowed", s)
+		}
+	}
+}
+
+func TestApplicationStatusKinds(t *testing.T) {
+	tests := []struct {
+		status            ApplicationStatus
+		initial, terminal bool
+	}{
+		{StatusDraft, true, false},
+		{StatusSubmitted, true, false},
+		{StatusUnderReview, false, false},
+		{StatusApproved, false, false},
+		{StatusRejected, false, true},
+		{StatusWithdrawn, false, true},
+		{StatusDisbursed, false, true},
+		{"pending", false, false},
+	}
+	for _, tt := range tests {
+		if got := tt.status.IsInitial(); got != tt.initial {
+			t.Errorf("%q initial = %v, want %v", tt.status, got, tt.initial)
+		}
+		if got := tt.status.IsTerminal(); got != tt.terminal {
+			t.Errorf("%q terminal = %v, want %v", tt.status, got, tt.terminal)
+		}
+	}
+}
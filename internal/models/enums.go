@@ -0,0 +1,123 @@
+package models
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+type EmploymentStatus string
+
+const (
+	EmploymentEmployed     EmploymentStatus = "employed"
+	EmploymentUnemployed   EmploymentStatus = "unemployed"
+	EmploymentSelfEmployed EmploymentStatus = "self_employed"
+	EmploymentRetired      EmploymentStatus = "retired"
+	EmploymentStudent      EmploymentStatus = "student"
+)
+
+var EmploymentStatuses = []EmploymentStatus{
+	EmploymentEmployed, EmploymentUnemployed, EmploymentSelfEmployed, EmploymentRetired, EmploymentStudent,
+}
+
+type MaritalStatus string
+
+const (
+	MaritalSingle    MaritalStatus = "single"
+	MaritalMarried   MaritalStatus = "married"
+	MaritalDivorced  MaritalStatus = "divorced"
+	MaritalWidowed   MaritalStatus = "widowed"
+	MaritalSeparated MaritalStatus = "separated"
+)
+
+var MaritalStatuses = []MaritalStatus{
+	MaritalSingle, MaritalMarried, MaritalDivorced, MaritalWidowed, MaritalSeparated,
+}
+
+type Sex string
+
+const (
+	SexMale   Sex = "male"
+	SexFemale Sex = "female"
+)
+
+var Sexes = []Sex{SexMale, SexFemale}
+
+// Relation is a household member's relationship to the applicant.
+type Relation string
+
+const (
+	RelationSpouse      Relation = "spouse"
+	RelationSon         Relation = "son"
+	RelationDaughter    Relation = "daughter"
+	RelationFather      Relation = "father"
+	RelationMother      Relation = "mother"
+	RelationBrother     Relation = "brother"
+	RelationSister      Relation = "sister"
+	RelationGrandparent Relation = "grandparent"
+	RelationGrandchild  Relation = "grandchild"
+	RelationOther       Relation = "other"
+)
+
+var Relations = []Relation{
+	RelationSpouse, RelationSon, RelationDaughter, RelationFather, RelationMother,
+	RelationBrother, RelationSister, RelationGrandparent, RelationGrandchild, RelationOther,
+}
+
+func (r Relation) IsChild() bool {
+	return r == RelationSon || r == RelationDaughter
+}
+
+type SchoolLevel string
+
+const (
+	SchoolNone          SchoolLevel = "none"
+	SchoolPreschool     SchoolLevel = "preschool"
+	SchoolPrimary       SchoolLevel = "primary"
+	SchoolSecondary     SchoolLevel = "secondary"
+	SchoolPostSecondary SchoolLevel = "post_secondary"
+	SchoolTertiary      SchoolLevel = "tertiary"
+)
+
+var SchoolLevels = []SchoolLevel{
+	SchoolNone, SchoolPreschool, SchoolPrimary, SchoolSecondary, SchoolPostSecondary, SchoolTertiary,
+}
+
+func (s EmploymentStatus) Valid() bool { return slices.Contains(EmploymentStatuses, s) }
+func (s MaritalStatus) Valid() bool    { return slices.Contains(MaritalStatuses, s) }
+func (s Sex) Valid() bool              { return slices.Contains(Sexes, s) }
+func (r Relation) Valid() bool         { return slices.Contains(Relations, r) }
+func (s SchoolLevel) Valid() bool      { return slices.Contains(SchoolLevels, s) }
+
+// Enumerated values are accepted in any case and with spaces or hyphens in
+// place of underscores, so "Self-Employed" decodes as "self_employed".
+// Whether the result is a known value is checked during validation.
+
+func (s *EmploymentStatus) UnmarshalJSON(data []byte) error { return unmarshalEnum(data, (*string)(s)) }
+func (s *MaritalStatus) UnmarshalJSON(data []byte) error    { return unmarshalEnum(data, (*string)(s)) }
+func (s *Sex) UnmarshalJSON(data []byte) error              { return unmarshalEnum(data, (*string)(s)) }
+func (r *Relation) UnmarshalJSON(data []byte) error         { return unmarshalEnum(data, (*string)(r)) }
+func (s *SchoolLevel) UnmarshalJSON(data []byte) error      { return unmarshalEnum(data, (*string)(s)) }
+
+func unmarshalEnum(data []byte, dst *string) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*dst = NormalizeEnum(value)
+	return nil
+}
+
+// NormalizeEnum converts a free-text enumerated value to its canonical form.
+func NormalizeEnum(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(value)
+}
+
+func joinValues[T ~string](values []T) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = string(v)
+	}
+	return strings.Join(parts, ", ")
+}
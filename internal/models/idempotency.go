@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// IdempotencyRecord remembers a request made with an Idempotency-Key and,
+// once it has finished, the response to replay for repeats of it.
+type IdempotencyRecord struct {
+	Scope       string
+	Key         string
+	Fingerprint string
+
+	// StatusCode is zero while the original request is still in progress.
+	StatusCode  int
+	ContentType string
+	Location    string
+	ETag        string
+	Body        []byte
+
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+func (r *IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"encoding/json"
+
 	"github.com/google/uuid"
 )
 
@@ -9,12 +11,64 @@ type Scheme struct {
 	Name     string    `json:"name" db:"name"`
 	Criteria Criteria  `json:"criteria,omitempty"`
 	Benefits []Benefit `json:"benefits,omitempty"`
+
+	// An applicant whose latest application for the scheme was rejected may
+	// only apply again when AllowReapplyAfterRejection is set. After any
+	// closed application, a new one is accepted only once ReapplyCooldownDays
+	// have passed since it closed.
+	AllowReapplyAfterRejection bool `json:"allow_reapply_after_rejection" db:"allow_reapply_after_rejection"`
+	ReapplyCooldownDays        int  `json:"reapply_cooldown_days" db:"reapply_cooldown_days"`
+
+	// Version counts the changes made to the scheme, its criteria and its
+	// benefits. It is served as the ETag.
+	Version int `json:"version" db:"version"`
 }
 
+// Criteria fields left nil (null, absent or an empty string in JSON, NULL in
+// the database) place no restriction on applicants. HasChildren set to false
+// requires an applicant without children.
 type Criteria struct {
-	EmploymentStatus string `json:"employment_status" db:"employment_status"`
-	MaritalStatus    string `json:"marital_status" db:"marital_status"`
-	HasChildren      bool   `json:"has_children" db:"has_children"`
+	EmploymentStatus *EmploymentStatus `json:"employment_status,omitempty" db:"employment_status"`
+	MaritalStatus    *MaritalStatus    `json:"marital_status,omitempty" db:"marital_status"`
+	HasChildren      *bool             `json:"has_children,omitempty" db:"has_children"`
+
+	// Age bounds are inclusive, in whole years; nil leaves the bound open.
+	MinApplicantAge       *int `json:"min_applicant_age,omitempty" db:"min_applicant_age"`
+	MaxApplicantAge       *int `json:"max_applicant_age,omitempty" db:"max_applicant_age"`
+	HouseholdMemberMinAge *int `json:"household_member_min_age,omitempty" db:"household_member_min_age"`
+	HouseholdMemberMaxAge *int `json:"household_member_max_age,omitempty" db:"household_member_max_age"`
+
+	// Monthly income ceilings (inclusive); nil means the scheme is not means-tested on that measure.
+	MaxHouseholdIncome *float64 `json:"max_household_income,omitempty" db:"max_household_income"`
+	MaxPerCapitaIncome *float64 `json:"max_per_capita_income,omitempty" db:"max_per_capita_income"`
+}
+
+func (c *Criteria) UnmarshalJSON(data []byte) error {
+	type Alias Criteria
+	if err := json.Unmarshal(data, (*Alias)(c)); err != nil {
+		return err
+	}
+
+	c.Normalize()
+	return nil
+}
+
+// Normalize brings enumerated criteria into their canonical form and clears
+// empty ones, so that every repository stores "any" the same way.
+func (c *Criteria) Normalize() {
+	c.EmploymentStatus = normalizeCriterion(c.EmploymentStatus)
+	c.MaritalStatus = normalizeCriterion(c.MaritalStatus)
+}
+
+func normalizeCriterion[T ~string](value *T) *T {
+	if value == nil {
+		return nil
+	}
+	normalized := T(NormalizeEnum(string(*value)))
+	if normalized == "" {
+		return nil
+	}
+	return &normalized
 }
 
 type Benefit struct {
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role determines what a user or API key may do; see auth.RolePermissions.
+type Role string
+
+const (
+	RoleAdmin      Role = "admin"
+	RoleCaseworker Role = "caseworker"
+	RoleAuditor    Role = "auditor"
+)
+
+var Roles = []Role{RoleAdmin, RoleCaseworker, RoleAuditor}
+
+func (r Role) Valid() bool { return slices.Contains(Roles, r) }
+
+// User is a staff account that logs in with a username and password.
+type User struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	Username     string    `json:"username" db:"username"`
+	PasswordHash string    `json:"-" db:"password_hash"`
+	Role         Role      `json:"role" db:"role"`
+	Disabled     bool      `json:"disabled" db:"disabled"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// APIKey is a long-lived credential for a system integration. Only a hash of
+// the key is stored; Prefix is the start of the key, kept so that keys can
+// be told apart.
+type APIKey struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	Name      string     `json:"name" db:"name"`
+	Prefix    string     `json:"prefix" db:"prefix"`
+	Hash      string     `json:"-" db:"key_hash"`
+	Role      Role       `json:"role" db:"role"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+}
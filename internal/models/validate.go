@@ -0,0 +1,182 @@
+package models
+
+import (
+	"financial_assistance/internal/apperror"
+	"fmt"
+	"time"
+)
+
+// fieldErrors collects every problem with a request body so that they can be
+// reported together.
+type fieldErrors struct {
+	prefix string
+	errs   []apperror.FieldError
+}
+
+func (e *fieldErrors) add(field, code, format string, args ...any) {
+	e.errs = append(e.errs, apperror.FieldError{
+		Field:   e.prefix + field,
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (e *fieldErrors) required(field string, present bool) {
+	if !present {
+		e.add(field, "required", "is required")
+	}
+}
+
+func (e *fieldErrors) nonNegative(field string, value float64) {
+	if value < 0 {
+		e.add(field, "negative", "must not be negative")
+	}
+}
+
+func (e *fieldErrors) dateOfBirth(field string, value, today time.Time) {
+	switch {
+	case value.IsZero():
+		e.add(field, "required", "is required")
+	case value.After(today):
+		e.add(field, "future_date", "must not be in the future")
+	}
+}
+
+// enum checks an optional enumerated value; use required first for
+// mandatory ones.
+func enum[T interface {
+	~string
+	Valid() bool
+}](e *fieldErrors, field string, value T, values []T) {
+	if value != "" && !value.Valid() {
+		e.add(field, "invalid_value", "must be one of %s", joinValues(values))
+	}
+}
+
+func (e *fieldErrors) err() error {
+	return apperror.Fields(e.errs)
+}
+
+// Validate checks the applicant and its household as of today.
+func (a *Applicant) Validate(today time.Time) error {
+	var e fieldErrors
+	a.validate(&e, today)
+	return e.err()
+}
+
+func (a *Applicant) validate(e *fieldErrors, today time.Time) {
+	e.required("name", a.Name != "")
+	if a.NationalID != "" && !validNationalID(a.NationalID) {
+		e.add("national_id", "invalid_value", "must be up to %d letters and digits", MaxNationalIDLength)
+	}
+	e.required("employment_status", a.EmploymentStatus != "")
+	enum(e, "employment_status", a.EmploymentStatus, EmploymentStatuses)
+	e.required("marital_status", a.MaritalStatus != "")
+	enum(e, "marital_status", a.MaritalStatus, MaritalStatuses)
+	e.required("sex", a.Sex != "")
+	enum(e, "sex", a.Sex, Sexes)
+	e.dateOfBirth("date_of_birth", a.DateOfBirth, today)
+	e.nonNegative("monthly_income", a.MonthlyIncome)
+
+	prefix := e.prefix
+	for i := range a.HouseholdMembers {
+		e.prefix = fmt.Sprintf("%shousehold[%d].", prefix, i)
+		a.HouseholdMembers[i].validate(e, today)
+	}
+	e.prefix = prefix
+}
+
+func (m *HouseholdMember) Validate(today time.Time) error {
+	var e fieldErrors
+	m.validate(&e, today)
+	return e.err()
+}
+
+func (m *HouseholdMember) validate(e *fieldErrors, today time.Time) {
+	e.required("name", m.Name != "")
+	e.required("relation", m.Relation != "")
+	enum(e, "relation", m.Relation, Relations)
+	e.required("sex", m.Sex != "")
+	enum(e, "sex", m.Sex, Sexes)
+	e.dateOfBirth("date_of_birth", m.DateOfBirth, today)
+	enum(e, "employment_status", m.EmploymentStatus, EmploymentStatuses)
+	enum(e, "school_level", m.SchoolLevel, SchoolLevels)
+	e.nonNegative("monthly_income", m.MonthlyIncome)
+}
+
+func (s *Scheme) Validate() error {
+	var e fieldErrors
+	e.required("name", s.Name != "")
+	if s.ReapplyCooldownDays < 0 {
+		e.add("reapply_cooldown_days", "negative", "must not be negative")
+	}
+
+	e.prefix = "criteria."
+	s.Criteria.validate(&e)
+
+	for i := range s.Benefits {
+		e.prefix = fmt.Sprintf("benefits[%d].", i)
+		s.Benefits[i].validate(&e)
+	}
+	return e.err()
+}
+
+func (c *Criteria) Validate() error {
+	var e fieldErrors
+	c.validate(&e)
+	return e.err()
+}
+
+func (c *Criteria) validate(e *fieldErrors) {
+	if c.EmploymentStatus != nil {
+		enum(e, "employment_status", *c.EmploymentStatus, EmploymentStatuses)
+	}
+	if c.MaritalStatus != nil {
+		enum(e, "marital_status", *c.MaritalStatus, MaritalStatuses)
+	}
+
+	ageRange(e, "min_applicant_age", "max_applicant_age", c.MinApplicantAge, c.MaxApplicantAge)
+	ageRange(e, "household_member_min_age", "household_member_max_age", c.HouseholdMemberMinAge, c.HouseholdMemberMaxAge)
+
+	if c.MaxHouseholdIncome != nil {
+		e.nonNegative("max_household_income", *c.MaxHouseholdIncome)
+	}
+	if c.MaxPerCapitaIncome != nil {
+		e.nonNegative("max_per_capita_income", *c.MaxPerCapitaIncome)
+	}
+}
+
+func ageRange(e *fieldErrors, minField, maxField string, minAge, maxAge *int) {
+	if minAge != nil && *minAge < 0 {
+		e.add(minField, "negative", "must not be negative")
+	}
+	if maxAge != nil && *maxAge < 0 {
+		e.add(maxField, "negative", "must not be negative")
+	}
+	if minAge != nil && maxAge != nil && *minAge > *maxAge {
+		e.add(minField, "invalid_range", "must not exceed %s", maxField)
+	}
+}
+
+func (b *Benefit) Validate() error {
+	var e fieldErrors
+	b.validate(&e)
+	return e.err()
+}
+
+func (b *Benefit) validate(e *fieldErrors) {
+	e.required("name", b.Name != "")
+	e.nonNegative("amount", b.Amount)
+}
+
+func validNationalID(id string) bool {
+	if len(id) > MaxNationalIDLength {
+		return false
+	}
+	for _, r := range id {
+		if !('A' <= r && r <= 'Z' || '0' <= r && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
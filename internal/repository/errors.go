@@ -0,0 +1,36 @@
+package repository
+
+import "financial_assistance/internal/apperror"
+
+var (
+	ErrApplicantNotFound       = apperror.NotFound("applicant_not_found", "applicant not found")
+	ErrHouseholdMemberNotFound = apperror.NotFound("household_member_not_found", "household member not found")
+	ErrSchemeNotFound          = apperror.NotFound("scheme_not_found", "scheme not found")
+	ErrBenefitNotFound         = apperror.NotFound("benefit_not_found", "benefit not found")
+	ErrApplicationNotFound     = apperror.NotFound("application_not_found", "application not found")
+	ErrUserNotFound            = apperror.NotFound("user_not_found", "user not found")
+	ErrAPIKeyNotFound          = apperror.NotFound("api_key_not_found", "API key not found")
+
+	// ErrUsernameTaken is returned when creating a user whose username is
+	// already in use.
+	ErrUsernameTaken = apperror.Conflict("username_taken", "username is already taken")
+
+	// ErrAlreadyExists is returned when creating a record whose ID is taken.
+	ErrAlreadyExists = apperror.Conflict("already_exists", "a record with this ID already exists")
+
+	// ErrStatusChanged is returned when an application's status no longer
+	// matches the status a caller expected to transition from.
+	ErrStatusChanged = apperror.Conflict("status_changed", "application status was changed concurrently")
+
+	// ErrActiveApplicationExists is returned when the applicant already has a
+	// non-terminal application for the same scheme.
+	ErrActiveApplicationExists = apperror.Conflict("active_application_exists", "an active application already exists for this applicant and scheme")
+
+	// ErrVersionMismatch is returned when a record's version is not the one
+	// a caller expected to change.
+	ErrVersionMismatch = apperror.PreconditionFailed("version_mismatch", "record version does not match")
+
+	// ErrReferenced is returned when deleting a record that other records
+	// still refer to.
+	ErrReferenced = apperror.Conflict("referenced", "record is still referenced")
+)
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"financial_assistance/internal/apperror"
+	"financial_assistance/internal/models"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+var ErrInvalidPage = apperror.Validation("invalid_page", "invalid page request")
+
+// Sort fields accepted by each list method. The first one is the default.
+// Applicants cannot be sorted by name or date of birth since those are
+// stored encrypted.
+var (
+	ApplicantSortFields   = []string{"id"}
+	SchemeSortFields      = []string{"id", "name"}
+	ApplicationSortFields = []string{"created_at", "updated_at", "id"}
+)
+
+// Page describes the slice of a list a caller wants. Sort names a sort
+// field, prefixed with "-" for descending order, and Cursor is the
+// NextCursor returned with the previous page.
+type Page struct {
+	Limit  int
+	Cursor string
+	Sort   string
+}
+
+// ApplicantFilter selects applicants. NationalID, and Name together with
+// DateOfBirth, match exactly after normalization (see
+// models.NormalizeNationalID and models.NormalizeName).
+type ApplicantFilter struct {
+	EmploymentStatus models.EmploymentStatus
+	MaritalStatus    models.MaritalStatus
+	NationalID       string
+	Name             string
+	DateOfBirth      time.Time
+	Page
+}
+
+type SchemeFilter struct {
+	Page
+}
+
+type ApplicationFilter struct {
+	Status      models.ApplicationStatus
+	ApplicantID uuid.UUID
+	SchemeID    uuid.UUID
+	Page
+}
+
+// PageQuery is a validated Page. After is nil on the first page.
+type PageQuery struct {
+	Limit int
+	Field string
+	Desc  bool
+	After *Cursor
+}
+
+// Cursor identifies the last row of a page by its sort value and ID, so the
+// next page can resume after it (keyset pagination).
+type Cursor struct {
+	Field string    `json:"f"`
+	Desc  bool      `json:"d,omitempty"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+func (p Page) Resolve(fields []string) (PageQuery, error) {
+	q := PageQuery{Limit: p.Limit, Field: fields[0]}
+
+	switch {
+	case q.Limit == 0:
+		q.Limit = DefaultPageLimit
+	case q.Limit < 0:
+		return q, fmt.Errorf("%w: limit must be positive", ErrInvalidPage)
+	case q.Limit > MaxPageLimit:
+		q.Limit = MaxPageLimit
+	}
+
+	if p.Sort != "" {
+		q.Field, q.Desc = strings.TrimPrefix(p.Sort, "-"), strings.HasPrefix(p.Sort, "-")
+		if !slices.Contains(fields, q.Field) {
+			return q, fmt.Errorf("%w: cannot sort by %q (expected one of %s)", ErrInvalidPage, q.Field, strings.Join(fields, ", "))
+		}
+	}
+
+	if p.Cursor != "" {
+		after, err := decodeCursor(p.Cursor)
+		if err != nil || after.Field != q.Field || after.Desc != q.Desc {
+			return q, fmt.Errorf("%w: cursor does not belong to this listing", ErrInvalidPage)
+		}
+		q.After = &after
+	}
+
+	return q, nil
+}
+
+// NextCursor returns the cursor resuming after the row with the given sort
+// value and ID.
+func (q PageQuery) NextCursor(value string, id uuid.UUID) string {
+	data, _ := json.Marshal(Cursor{Field: q.Field, Desc: q.Desc, Value: value, ID: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// TrimPage cuts items, fetched with one row more than the page size, down to
+// the page size and returns the cursor of the following page, if any.
+func TrimPage[T any](q PageQuery, items []T, sortValue func(*T, string) string, id func(*T) uuid.UUID) ([]T, string) {
+	if len(items) <= q.Limit {
+		return items, ""
+	}
+
+	items = items[:q.Limit]
+	last := &items[q.Limit-1]
+	return items, q.NextCursor(sortValue(last, q.Field), id(last))
+}
+
+func decodeCursor(s string) (Cursor, error) {
+	var c Cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(data, &c)
+	return c, err
+}
+
+// Sort values are rendered so that their byte order matches the order of the
+// underlying values, and so that PostgreSQL can compare them with the column.
+const sortTimestampLayout = "2006-01-02T15:04:05.000000000Z"
+
+func ApplicantSortValue(a *models.Applicant, field string) string {
+	return a.ID.String()
+}
+
+func SchemeSortValue(s *models.Scheme, field string) string {
+	if field == "name" {
+		return s.Name
+	}
+	return s.ID.String()
+}
+
+func ApplicationSortValue(a *models.Application, field string) string {
+	switch field {
+	case "created_at":
+		return formatSortTime(a.CreatedAt)
+	case "updated_at":
+		return formatSortTime(a.UpdatedAt)
+	}
+	return a.ID.String()
+}
+
+func formatSortTime(t time.Time) string {
+	return t.UTC().Format(sortTimestampLayout)
+}
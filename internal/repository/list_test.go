@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestResolvePage(t *testing.T) {
+	fields := []string{"created_at", "updated_at", "id"}
+	tests := []struct {
+		page  Page
+		limit int
+		field string
+		desc  bool
+	}{
+		{Page{}, DefaultPageLimit, "created_at", false},
+		{Page{Limit: 1}, 1, "created_at", false},
+		{Page{Limit: MaxPageLimit + 1}, MaxPageLimit, "created_at", false},
+		{Page{Sort: "id"}, DefaultPageLimit, "id", false},
+		{Page{Sort: "-updated_at", Limit: 5}, 5, "updated_at", true},
+	}
+	for _, tt := range tests {
+		q, err := tt.page.Resolve(fields)
+		if err != nil {
+			t.Errorf("Resolve(%+v): %v", tt.page, err)
+			continue
+		}
+		if q.Limit != tt.limit || q.Field != tt.field || q.Desc != tt.desc || q.After != nil {
+			t.Errorf("Resolve(%+v) = %+v, want limit %d, field %s, desc %v and no cursor", tt.page, q, tt.limit, tt.field, tt.desc)
+		}
+	}
+}
+
+func TestResolvePageRejects(t *testing.T) {
+	fields := []string{"id", "name"}
+	q := PageQuery{Limit: 10, Field: "name"}
+	valid := q.NextCursor("Universal Grant", uuid.New())
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name string
+		page Page
+	}{
+		{"negative limit", Page{Limit: -1}},
+		{"unknown sort field", Page{Sort: "date_of_birth"}},
+		{"descending unknown field", Page{Sort: "-created_at"}},
+		{"empty descending field", Page{Sort: "-"}},
+		{"cursor of another sort", Page{Sort: "id", Cursor: valid}},
+		{"cursor of another direction", Page{Sort: "-name", Cursor: valid}},
+		{"cursor of another listing", Page{Sort: "name", Cursor: PageQuery{Field: "created_at"}.NextCursor("x", uuid.New())}},
+		{"not base64", Page{Sort: "name", Cursor: "not a cursor!"}},
+		{"padded base64", Page{Sort: "name", Cursor: valid + "="}},
+		{"not JSON", Page{Sort: "name", Cursor: encode("name,Universal Grant")}},
+		{"bad ID", Page{Sort: "name", Cursor: encode(`{"f":"name","v":"x","id":"42"}`)}},
+		{"truncated", Page{Sort: "name", Cursor: valid[:len(valid)-4]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if q, err := tt.page.Resolve(fields); !errors.Is(err, ErrInvalidPage) {
+				t.Errorf("Resolve(%+v) = %+v, %v; want ErrInvalidPage", tt.page, q, err)
+			}
+		})
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	id := uuid.New()
+	for _, q := range []PageQuery{
+		{Limit: 10, Field: "name"},
+		{Limit: 10, Field: "name", Desc: true},
+	} {
+		cursor := q.NextCursor(`Grant "B", 50%`, id)
+		sort := q.Field
+		if q.Desc {
+			sort = "-" + sort
+		}
+		resolved, err := Page{Sort: sort, Cursor: cursor}.Resolve([]string{"id", "name"})
+		if err != nil {
+			t.Fatalf("Resolve with its own cursor: %v", err)
+		}
+		want := Cursor{Field: q.Field, Desc: q.Desc, Value: `Grant "B", 50%`, ID: id}
+		if resolved.After == nil || *resolved.After != want {
+			t.Errorf("cursor resolved to %+v, want %+v", resolved.After, want)
+		}
+	}
+}
+
+func TestTrimPage(t *testing.T) {
+	type item struct {
+		id    uuid.UUID
+		value string
+	}
+	items := []item{{uuid.New(), "a"}, {uuid.New(), "b"}, {uuid.New(), "c"}}
+	sortValue := func(i *item, field string) string { return i.value }
+	id := func(i *item) uuid.UUID { return i.id }
+	q := PageQuery{Limit: 2, Field: "name"}
+
+	page, next := TrimPage(q, items, sortValue, id)
+	if len(page) != 2 || next != q.NextCursor("b", items[1].id) {
+		t.Errorf("TrimPage of 3 items = %d items with cursor %q, want 2 with the cursor after b", len(page), next)
+	}
+	for _, n := range []int{0, 1, 2} {
+		if page, next := TrimPage(q, items[:n], sortValue, id); len(page) != n || next != "" {
+			t.Errorf("TrimPage of %d items = %d items with cursor %q, want all of them and no cursor", n, len(page), next)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKeyRepo struct {
+	store *Store
+}
+
+func NewAPIKeyRepo(store *Store) *APIKeyRepo {
+	return &APIKeyRepo{store: store}
+}
+
+func (r *APIKeyRepo) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.apiKeys[key.ID]; exists {
+		return repository.ErrAlreadyExists
+	}
+	for _, existing := range r.store.apiKeys {
+		if existing.Hash == key.Hash {
+			return repository.ErrAlreadyExists
+		}
+	}
+
+	r.store.apiKeys[key.ID] = copyAPIKey(*key)
+	return nil
+}
+
+func (r *APIKeyRepo) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, key := range r.store.apiKeys {
+		if key.Hash == hash {
+			key = copyAPIKey(key)
+			return &key, nil
+		}
+	}
+	return nil, repository.ErrAPIKeyNotFound
+}
+
+func (r *APIKeyRepo) GetAllAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	keys := make([]models.APIKey, 0, len(r.store.apiKeys))
+	for _, key := range r.store.apiKeys {
+		keys = append(keys, copyAPIKey(key))
+	}
+	slices.SortFunc(keys, func(a, b models.APIKey) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return slices.Compare(a.ID[:], b.ID[:])
+	})
+	return keys, nil
+}
+
+func (r *APIKeyRepo) RevokeAPIKey(ctx context.Context, id uuid.UUID, at time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key, ok := r.store.apiKeys[id]
+	if !ok {
+		return repository.ErrAPIKeyNotFound
+	}
+	if key.RevokedAt == nil {
+		key.RevokedAt = &at
+		r.store.apiKeys[id] = key
+	}
+	return nil
+}
+
+func copyAPIKey(k models.APIKey) models.APIKey {
+	k.RevokedAt = copyPtr(k.RevokedAt)
+	return k
+}
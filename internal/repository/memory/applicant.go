@@ -0,0 +1,208 @@
+package memory
+
+import (
+	"context"
+	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type ApplicantRepo struct {
+	store *Store
+}
+
+func NewApplicantRepo(store *Store) *ApplicantRepo {
+	return &ApplicantRepo{store: store}
+}
+
+func (r *ApplicantRepo) CreateApplicant(ctx context.Context, applicant *models.Applicant) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.applicants[applicant.ID]; exists {
+		return repository.ErrAlreadyExists
+	}
+	if r.store.memberIDsTaken(uuid.Nil, applicant.HouseholdMembers) {
+		return repository.ErrAlreadyExists
+	}
+
+	for i := range applicant.HouseholdMembers {
+		applicant.HouseholdMembers[i].ApplicantID = applicant.ID
+	}
+	applicant.Version = 1
+	stored := copyApplicant(*applicant)
+
+	r.store.applicants[applicant.ID] = stored
+	r.store.applicantOrder = append(r.store.applicantOrder, applicant.ID)
+	return nil
+}
+
+func (r *ApplicantRepo) GetAllApplicants(ctx context.Context, filter repository.ApplicantFilter) ([]models.Applicant, string, error) {
+	page, err := filter.Page.Resolve(repository.ApplicantSortFields)
+	if err != nil {
+		return nil, "", err
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var applicants []models.Applicant
+	for _, id := range r.store.applicantOrder {
+		app := r.store.applicants[id]
+		if filter.EmploymentStatus != "" && app.EmploymentStatus != filter.EmploymentStatus {
+			continue
+		}
+		if filter.MaritalStatus != "" && app.MaritalStatus != filter.MaritalStatus {
+			continue
+		}
+		if filter.NationalID != "" && app.NationalID != filter.NationalID {
+			continue
+		}
+		if filter.Name != "" && (models.NormalizeName(app.Name) != filter.Name ||
+			app.DateOfBirth.Format("2006-01-02") != filter.DateOfBirth.Format("2006-01-02")) {
+			continue
+		}
+		applicants = append(applicants, copyApplicant(app))
+	}
+
+	applicants, next := paginate(page, applicants, repository.ApplicantSortValue,
+		func(a *models.Applicant) uuid.UUID { return a.ID })
+	return applicants, next, nil
+}
+
+func (r *ApplicantRepo) GetApplicant(ctx context.Context, id uuid.UUID) (*models.Applicant, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	app, ok := r.store.applicants[id]
+	if !ok {
+		return nil, repository.ErrApplicantNotFound
+	}
+
+	app = copyApplicant(app)
+	return &app, nil
+}
+
+func (r *ApplicantRepo) UpdateApplicant(ctx context.Context, applicant *models.Applicant) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.applicants[applicant.ID]
+	if !ok {
+		return repository.ErrApplicantNotFound
+	}
+	if current.Version != applicant.Version {
+		return repository.ErrVersionMismatch
+	}
+	if r.store.memberIDsTaken(applicant.ID, applicant.HouseholdMembers) {
+		return repository.ErrAlreadyExists
+	}
+
+	for i := range applicant.HouseholdMembers {
+		applicant.HouseholdMembers[i].ApplicantID = applicant.ID
+	}
+	applicant.Version++
+	stored := copyApplicant(*applicant)
+
+	r.store.applicants[applicant.ID] = stored
+	return nil
+}
+
+func (r *ApplicantRepo) DeleteApplicant(ctx context.Context, id uuid.UUID, version int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	applicant, ok := r.store.applicants[id]
+	if !ok {
+		return repository.ErrApplicantNotFound
+	}
+	if applicant.Version != version {
+		return repository.ErrVersionMismatch
+	}
+	for _, application := range r.store.applications {
+		if application.ApplicantID == id {
+			return repository.ErrReferenced
+		}
+	}
+
+	delete(r.store.applicants, id)
+	r.store.applicantOrder = removeID(r.store.applicantOrder, id)
+	return nil
+}
+
+func (r *ApplicantRepo) AddHouseholdMember(ctx context.Context, applicantID uuid.UUID, member *models.HouseholdMember, version int) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	applicant, ok := r.store.applicants[applicantID]
+	if !ok {
+		return 0, repository.ErrApplicantNotFound
+	}
+	if applicant.Version != version {
+		return 0, repository.ErrVersionMismatch
+	}
+	if r.store.memberIDsTaken(uuid.Nil, []models.HouseholdMember{*member}) {
+		return 0, repository.ErrAlreadyExists
+	}
+
+	member.ApplicantID = applicantID
+	applicant = copyApplicant(applicant)
+	applicant.HouseholdMembers = append(applicant.HouseholdMembers, *member)
+	applicant.Version++
+	r.store.applicants[applicantID] = applicant
+	return applicant.Version, nil
+}
+
+func (r *ApplicantRepo) UpdateHouseholdMember(ctx context.Context, applicantID uuid.UUID, member *models.HouseholdMember, version int) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	applicant, ok := r.store.applicants[applicantID]
+	if !ok {
+		return 0, repository.ErrApplicantNotFound
+	}
+	if applicant.Version != version {
+		return 0, repository.ErrVersionMismatch
+	}
+
+	applicant = copyApplicant(applicant)
+	for i := range applicant.HouseholdMembers {
+		if applicant.HouseholdMembers[i].ID == member.ID {
+			member.ApplicantID = applicantID
+			applicant.HouseholdMembers[i] = *member
+			applicant.Version++
+			r.store.applicants[applicantID] = applicant
+			return applicant.Version, nil
+		}
+	}
+
+	return 0, repository.ErrHouseholdMemberNotFound
+}
+
+func (r *ApplicantRepo) DeleteHouseholdMember(ctx context.Context, applicantID, memberID uuid.UUID, version int) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	applicant, ok := r.store.applicants[applicantID]
+	if !ok {
+		return 0, repository.ErrApplicantNotFound
+	}
+	if applicant.Version != version {
+		return 0, repository.ErrVersionMismatch
+	}
+
+	for i, member := range applicant.HouseholdMembers {
+		if member.ID == memberID {
+			members := make([]models.HouseholdMember, 0, len(applicant.HouseholdMembers)-1)
+			members = append(members, applicant.HouseholdMembers[:i]...)
+			members = append(members, applicant.HouseholdMembers[i+1:]...)
+			applicant.HouseholdMembers = members
+			applicant.Version++
+			r.store.applicants[applicantID] = applicant
+			return applicant.Version, nil
+		}
+	}
+
+	return 0, repository.ErrHouseholdMemberNotFound
+}
@@ -0,0 +1,18 @@
+package memory
+
+import (
+	"financial_assistance/internal/repository/repotest"
+	"testing"
+)
+
+func TestApplicantLookup(t *testing.T) {
+	repotest.ApplicantLookup(t, NewApplicantRepo(NewStore()))
+}
+
+func TestApplicantFilters(t *testing.T) {
+	repotest.ApplicantFilters(t, NewApplicantRepo(NewStore()))
+}
+
+func TestHouseholdMembers(t *testing.T) {
+	repotest.HouseholdMembers(t, NewApplicantRepo(NewStore()))
+}
@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"context"
+	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type ApplicationRepo struct {
+	store *Store
+}
+
+func NewApplicationRepo(store *Store) *ApplicationRepo {
+	return &ApplicationRepo{store: store}
+}
+
+func (r *ApplicationRepo) CreateApplication(ctx context.Context, application *models.Application, event *models.ApplicationEvent) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.applications[application.ID]; exists {
+		return repository.ErrAlreadyExists
+	}
+	if _, ok := r.store.applicants[application.ApplicantID]; !ok {
+		return repository.ErrApplicantNotFound
+	}
+	if _, ok := r.store.schemes[application.SchemeID]; !ok {
+		return repository.ErrSchemeNotFound
+	}
+	for _, existing := range r.store.applications {
+		if existing.ApplicantID == application.ApplicantID &&
+			existing.SchemeID == application.SchemeID &&
+			!existing.Status.IsTerminal() {
+			return repository.ErrActiveApplicationExists
+		}
+	}
+
+	application.Version = 1
+	r.store.applications[application.ID] = *application
+	r.store.applicationOrder = append(r.store.applicationOrder, application.ID)
+	r.store.applicationEvents[application.ID] = []models.ApplicationEvent{*event}
+	return nil
+}
+
+func (r *ApplicationRepo) GetApplication(ctx context.Context, id uuid.UUID) (*models.Application, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	app, ok := r.store.applications[id]
+	if !ok {
+		return nil, repository.ErrApplicationNotFound
+	}
+
+	return &app, nil
+}
+
+func (r *ApplicationRepo) GetAllApplications(ctx context.Context, filter repository.ApplicationFilter) ([]models.Application, string, error) {
+	page, err := filter.Page.Resolve(repository.ApplicationSortFields)
+	if err != nil {
+		return nil, "", err
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var applications []models.Application
+	for _, id := range r.store.applicationOrder {
+		app := r.store.applications[id]
+		if filter.Status != "" && app.Status != filter.Status {
+			continue
+		}
+		if filter.ApplicantID != uuid.Nil && app.ApplicantID != filter.ApplicantID {
+			continue
+		}
+		if filter.SchemeID != uuid.Nil && app.SchemeID != filter.SchemeID {
+			continue
+		}
+		applications = append(applications, app)
+	}
+
+	applications, next := paginate(page, applications, repository.ApplicationSortValue,
+		func(a *models.Application) uuid.UUID { return a.ID })
+	return applications, next, nil
+}
+
+func (r *ApplicationRepo) GetApplicationsByApplicantAndScheme(ctx context.Context, applicantID, schemeID uuid.UUID) ([]models.Application, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var applications []models.Application
+	for _, id := range r.store.applicationOrder {
+		app := r.store.applications[id]
+		if app.ApplicantID == applicantID && app.SchemeID == schemeID {
+			applications = append(applications, app)
+		}
+	}
+
+	return applications, nil
+}
+
+func (r *ApplicationRepo) UpdateApplicationStatus(ctx context.Context, event *models.ApplicationEvent, version int) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	app, ok := r.store.applications[event.ApplicationID]
+	if !ok {
+		return 0, repository.ErrApplicationNotFound
+	}
+	if app.Version != version {
+		return 0, repository.ErrVersionMismatch
+	}
+	if app.Status != event.FromStatus {
+		return 0, repository.ErrStatusChanged
+	}
+
+	app.Status = event.ToStatus
+	app.UpdatedAt = event.CreatedAt
+	app.Version++
+	r.store.applications[app.ID] = app
+	r.store.applicationEvents[app.ID] = append(r.store.applicationEvents[app.ID], *event)
+	return app.Version, nil
+}
+
+func (r *ApplicationRepo) GetApplicationEvents(ctx context.Context, applicationID uuid.UUID) ([]models.ApplicationEvent, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	stored := r.store.applicationEvents[applicationID]
+	if len(stored) == 0 {
+		return nil, nil
+	}
+
+	events := make([]models.ApplicationEvent, len(stored))
+	copy(events, stored)
+	return events, nil
+}
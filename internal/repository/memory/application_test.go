@@ -0,0 +1,21 @@
+package memory
+
+import (
+	"financial_assistance/internal/repository/repotest"
+	"testing"
+)
+
+func TestApplicationPages(t *testing.T) {
+	store := NewStore()
+	repotest.ApplicationPages(t, NewApplicantRepo(store), NewSchemeRepo(store), NewApplicationRepo(store))
+}
+
+func TestApplicationHistory(t *testing.T) {
+	store := NewStore()
+	repotest.ApplicationHistory(t, NewApplicantRepo(store), NewSchemeRepo(store), NewApplicationRepo(store))
+}
+
+func TestApplicationCRUD(t *testing.T) {
+	store := NewStore()
+	repotest.ApplicationCRUD(t, NewApplicantRepo(store), NewSchemeRepo(store), NewApplicationRepo(store))
+}
@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+	"financial_assistance/internal/models"
+	"slices"
+	"time"
+)
+
+type IdempotencyRepo struct {
+	store *Store
+}
+
+func NewIdempotencyRepo(store *Store) *IdempotencyRepo {
+	return &IdempotencyRepo{store: store}
+}
+
+type idempotencyKey struct {
+	scope string
+	key   string
+}
+
+func (r *IdempotencyRepo) ReserveIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord) (*models.IdempotencyRecord, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	k := idempotencyKey{record.Scope, record.Key}
+	if existing, ok := r.store.idempotencyKeys[k]; ok && existing.ExpiresAt.After(record.CreatedAt) {
+		existing.Body = slices.Clone(existing.Body)
+		return &existing, nil
+	}
+
+	r.store.idempotencyKeys[k] = *record
+	return nil, nil
+}
+
+func (r *IdempotencyRepo) CompleteIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	k := idempotencyKey{record.Scope, record.Key}
+	if _, ok := r.store.idempotencyKeys[k]; ok {
+		stored := *record
+		stored.Body = slices.Clone(record.Body)
+		r.store.idempotencyKeys[k] = stored
+	}
+	return nil
+}
+
+func (r *IdempotencyRepo) ReleaseIdempotencyKey(ctx context.Context, scope, key string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	k := idempotencyKey{scope, key}
+	if existing, ok := r.store.idempotencyKeys[k]; ok && !existing.Completed() {
+		delete(r.store.idempotencyKeys, k)
+	}
+	return nil
+}
+
+func (r *IdempotencyRepo) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var deleted int64
+	for k, record := range r.store.idempotencyKeys {
+		if !record.ExpiresAt.After(before) {
+			delete(r.store.idempotencyKeys, k)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"cmp"
+	"financial_assistance/internal/repository"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// paginate orders items the way the postgres repositories do, by sort value
+// and then ID, and returns the page following q.After.
+func paginate[T any](q repository.PageQuery, items []T, sortValue func(*T, string) string, id func(*T) uuid.UUID) ([]T, string) {
+	compare := func(value string, itemID uuid.UUID, otherValue string, otherID uuid.UUID) int {
+		c := cmp.Or(
+			strings.Compare(value, otherValue),
+			strings.Compare(itemID.String(), otherID.String()),
+		)
+		if q.Desc {
+			return -c
+		}
+		return c
+	}
+
+	slices.SortFunc(items, func(a, b T) int {
+		return compare(sortValue(&a, q.Field), id(&a), sortValue(&b, q.Field), id(&b))
+	})
+
+	start := 0
+	if q.After != nil {
+		start = len(items)
+		for i := range items {
+			if compare(sortValue(&items[i], q.Field), id(&items[i]), q.After.Value, q.After.ID) > 0 {
+				start = i
+				break
+			}
+		}
+	}
+
+	items = items[start:]
+	if len(items) > q.Limit+1 {
+		items = items[:q.Limit+1]
+	}
+	return repository.TrimPage(q, items, sortValue, id)
+}
@@ -0,0 +1,175 @@
+package memory
+
+import (
+	"context"
+	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type SchemeRepo struct {
+	store *Store
+}
+
+func NewSchemeRepo(store *Store) *SchemeRepo {
+	return &SchemeRepo{store: store}
+}
+
+func (r *SchemeRepo) GetAllSchemes(ctx context.Context, filter repository.SchemeFilter) ([]models.Scheme, string, error) {
+	page, err := filter.Page.Resolve(repository.SchemeSortFields)
+	if err != nil {
+		return nil, "", err
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var schemes []models.Scheme
+	for _, id := range r.store.schemeOrder {
+		schemes = append(schemes, copyScheme(r.store.schemes[id]))
+	}
+
+	schemes, next := paginate(page, schemes, repository.SchemeSortValue,
+		func(s *models.Scheme) uuid.UUID { return s.ID })
+	return schemes, next, nil
+}
+
+func (r *SchemeRepo) GetScheme(ctx context.Context, id uuid.UUID) (*models.Scheme, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	scheme, ok := r.store.schemes[id]
+	if !ok {
+		return nil, repository.ErrSchemeNotFound
+	}
+
+	scheme = copyScheme(scheme)
+	return &scheme, nil
+}
+
+func (r *SchemeRepo) CreateScheme(ctx context.Context, scheme *models.Scheme) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.schemes[scheme.ID]; exists {
+		return repository.ErrAlreadyExists
+	}
+	if r.store.benefitIDsTaken(uuid.Nil, scheme.Benefits) {
+		return repository.ErrAlreadyExists
+	}
+
+	scheme.Version = 1
+	r.store.schemes[scheme.ID] = copyScheme(*scheme)
+	r.store.schemeOrder = append(r.store.schemeOrder, scheme.ID)
+	return nil
+}
+
+func (r *SchemeRepo) UpdateScheme(ctx context.Context, scheme *models.Scheme) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	current, ok := r.store.schemes[scheme.ID]
+	if !ok {
+		return repository.ErrSchemeNotFound
+	}
+	if current.Version != scheme.Version {
+		return repository.ErrVersionMismatch
+	}
+	if r.store.benefitIDsTaken(scheme.ID, scheme.Benefits) {
+		return repository.ErrAlreadyExists
+	}
+
+	scheme.Version++
+	r.store.schemes[scheme.ID] = copyScheme(*scheme)
+	return nil
+}
+
+func (r *SchemeRepo) DeleteScheme(ctx context.Context, id uuid.UUID, version int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	scheme, ok := r.store.schemes[id]
+	if !ok {
+		return repository.ErrSchemeNotFound
+	}
+	if scheme.Version != version {
+		return repository.ErrVersionMismatch
+	}
+	for _, application := range r.store.applications {
+		if application.SchemeID == id {
+			return repository.ErrReferenced
+		}
+	}
+
+	delete(r.store.schemes, id)
+	r.store.schemeOrder = removeID(r.store.schemeOrder, id)
+	return nil
+}
+
+func (r *SchemeRepo) UpdateCriteria(ctx context.Context, schemeID uuid.UUID, criteria *models.Criteria, version int) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	scheme, ok := r.store.schemes[schemeID]
+	if !ok {
+		return 0, repository.ErrSchemeNotFound
+	}
+	if scheme.Version != version {
+		return 0, repository.ErrVersionMismatch
+	}
+
+	scheme.Criteria = copyCriteria(*criteria)
+	scheme.Version++
+	r.store.schemes[schemeID] = scheme
+	return scheme.Version, nil
+}
+
+func (r *SchemeRepo) AddBenefit(ctx context.Context, schemeID uuid.UUID, benefit *models.Benefit, version int) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	scheme, ok := r.store.schemes[schemeID]
+	if !ok {
+		return 0, repository.ErrSchemeNotFound
+	}
+	if scheme.Version != version {
+		return 0, repository.ErrVersionMismatch
+	}
+	if r.store.benefitIDsTaken(uuid.Nil, []models.Benefit{*benefit}) {
+		return 0, repository.ErrAlreadyExists
+	}
+
+	scheme = copyScheme(scheme)
+	scheme.Benefits = append(scheme.Benefits, *benefit)
+	scheme.Version++
+	r.store.schemes[schemeID] = scheme
+	return scheme.Version, nil
+}
+
+func (r *SchemeRepo) DeleteBenefit(ctx context.Context, schemeID, benefitID uuid.UUID, version int) (int, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	scheme, ok := r.store.schemes[schemeID]
+	if !ok {
+		return 0, repository.ErrSchemeNotFound
+	}
+	if scheme.Version != version {
+		return 0, repository.ErrVersionMismatch
+	}
+
+	for i, benefit := range scheme.Benefits {
+		if benefit.ID == benefitID {
+			benefits := make([]models.Benefit, 0, len(scheme.Benefits)-1)
+			benefits = append(benefits, scheme.Benefits[:i]...)
+			benefits = append(benefits, scheme.Benefits[i+1:]...)
+			scheme.Benefits = benefits
+			scheme.Version++
+			r.store.schemes[schemeID] = scheme
+			return scheme.Version, nil
+		}
+	}
+
+	return 0, repository.ErrBenefitNotFound
+}
@@ -0,0 +1,18 @@
+package memory
+
+import (
+	"financial_assistance/internal/repository/repotest"
+	"testing"
+)
+
+func TestSchemeRoundTrip(t *testing.T) {
+	repotest.SchemeRoundTrip(t, NewSchemeRepo(NewStore()))
+}
+
+func TestSchemeNameOrder(t *testing.T) {
+	repotest.SchemeNameOrder(t, NewSchemeRepo(NewStore()))
+}
+
+func TestSchemePages(t *testing.T) {
+	repotest.SchemePages(t, NewSchemeRepo(NewStore()))
+}
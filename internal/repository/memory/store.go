@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"financial_assistance/internal/models"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+type Store struct {
+	mu sync.RWMutex
+
+	applicants     map[uuid.UUID]models.Applicant
+	applicantOrder []uuid.UUID
+
+	schemes     map[uuid.UUID]models.Scheme
+	schemeOrder []uuid.UUID
+
+	applications      map[uuid.UUID]models.Application
+	applicationOrder  []uuid.UUID
+	applicationEvents map[uuid.UUID][]models.ApplicationEvent
+
+	users   map[uuid.UUID]models.User
+	apiKeys map[uuid.UUID]models.APIKey
+
+	idempotencyKeys map[idempotencyKey]models.IdempotencyRecord
+}
+
+func NewStore() *Store {
+	return &Store{
+		applicants:        make(map[uuid.UUID]models.Applicant),
+		schemes:           make(map[uuid.UUID]models.Scheme),
+		applications:      make(map[uuid.UUID]models.Application),
+		applicationEvents: make(map[uuid.UUID][]models.ApplicationEvent),
+		users:             make(map[uuid.UUID]models.User),
+		apiKeys:           make(map[uuid.UUID]models.APIKey),
+		idempotencyKeys:   make(map[idempotencyKey]models.IdempotencyRecord),
+	}
+}
+
+// memberIDsTaken reports whether members repeat an ID among themselves or
+// use one that belongs to a household member of an applicant other than
+// owner. Pass uuid.Nil as owner to check against every household.
+func (s *Store) memberIDsTaken(owner uuid.UUID, members []models.HouseholdMember) bool {
+	ids := make(map[uuid.UUID]bool, len(members))
+	for _, member := range members {
+		if ids[member.ID] {
+			return true
+		}
+		ids[member.ID] = true
+	}
+
+	for id, applicant := range s.applicants {
+		if id == owner {
+			continue
+		}
+		for _, member := range applicant.HouseholdMembers {
+			if ids[member.ID] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// benefitIDsTaken is memberIDsTaken for scheme benefits.
+func (s *Store) benefitIDsTaken(owner uuid.UUID, benefits []models.Benefit) bool {
+	ids := make(map[uuid.UUID]bool, len(benefits))
+	for _, benefit := range benefits {
+		if ids[benefit.ID] {
+			return true
+		}
+		ids[benefit.ID] = true
+	}
+
+	for id, scheme := range s.schemes {
+		if id == owner {
+			continue
+		}
+		for _, benefit := range scheme.Benefits {
+			if ids[benefit.ID] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func copyApplicant(a models.Applicant) models.Applicant {
+	if a.HouseholdMembers != nil {
+		members := make([]models.HouseholdMember, len(a.HouseholdMembers))
+		copy(members, a.HouseholdMembers)
+		a.HouseholdMembers = members
+	}
+	return a
+}
+
+func copyScheme(s models.Scheme) models.Scheme {
+	s.Criteria = copyCriteria(s.Criteria)
+	if s.Benefits != nil {
+		benefits := make([]models.Benefit, len(s.Benefits))
+		copy(benefits, s.Benefits)
+		s.Benefits = benefits
+	}
+	return s
+}
+
+func copyCriteria(c models.Criteria) models.Criteria {
+	c.EmploymentStatus = copyPtr(c.EmploymentStatus)
+	c.MaritalStatus = copyPtr(c.MaritalStatus)
+	c.HasChildren = copyPtr(c.HasChildren)
+	c.MinApplicantAge = copyPtr(c.MinApplicantAge)
+	c.MaxApplicantAge = copyPtr(c.MaxApplicantAge)
+	c.HouseholdMemberMinAge = copyPtr(c.HouseholdMemberMinAge)
+	c.HouseholdMemberMaxAge = copyPtr(c.HouseholdMemberMaxAge)
+	c.MaxHouseholdIncome = copyPtr(c.MaxHouseholdIncome)
+	c.MaxPerCapitaIncome = copyPtr(c.MaxPerCapitaIncome)
+	return c
+}
+
+func copyPtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+func removeID(ids []uuid.UUID, id uuid.UUID) []uuid.UUID {
+	for i, existing := range ids {
+		if existing == id {
+			return append(ids[:i:i], ids[i+1:]...)
+		}
+	}
+	return ids
+}
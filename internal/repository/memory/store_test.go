@@ -0,0 +1,199 @@
+package memory
+
+import (
+	"context"
+	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newID() uuid.UUID { return uuid.Must(uuid.NewV7()) }
+
+// TestApplicantIsolation checks that applicants handed to or returned by the
+// store share no memory with the stored copy.
+func TestApplicantIsolation(t *testing.T) {
+	ctx := context.Background()
+	repo := NewApplicantRepo(NewStore())
+	applicant := &models.Applicant{
+		ID:               newID(),
+		Name:             "Mary Tan",
+		EmploymentStatus: models.EmploymentUnemployed,
+		MaritalStatus:    models.MaritalMarried,
+		Sex:              models.SexFemale,
+		DateOfBirth:      time.Date(1985, time.March, 14, 0, 0, 0, 0, time.UTC),
+		HouseholdMembers: []models.HouseholdMember{{ID: newID(), Name: "Gwen Tan", Relation: models.RelationDaughter}},
+	}
+	if err := repo.CreateApplicant(ctx, applicant); err != nil {
+		t.Fatalf("CreateApplicant: %v", err)
+	}
+	expectMember := func(when string) {
+		t.Helper()
+		stored, err := repo.GetApplicant(ctx, applicant.ID)
+		if err != nil {
+			t.Fatalf("GetApplicant: %v", err)
+		}
+		if len(stored.HouseholdMembers) != 1 || stored.HouseholdMembers[0].Name != "Gwen Tan" {
+			t.Errorf("after %s the stored household is %+v, want Gwen Tan alone", when, stored.HouseholdMembers)
+		}
+	}
+
+	applicant.HouseholdMembers[0].Name = "changed"
+	expectMember("changing the created applicant")
+
+	got, err := repo.GetApplicant(ctx, applicant.ID)
+	if err != nil {
+		t.Fatalf("GetApplicant: %v", err)
+	}
+	got.HouseholdMembers[0].Name = "changed"
+	got.HouseholdMembers = append(got.HouseholdMembers, models.HouseholdMember{ID: newID()})
+	expectMember("changing a fetched applicant")
+
+	listed, _, err := repo.GetAllApplicants(ctx, repository.ApplicantFilter{})
+	if err != nil {
+		t.Fatalf("GetAllApplicants: %v", err)
+	}
+	listed[0].HouseholdMembers[0].Name = "changed"
+	expectMember("changing a listed applicant")
+
+	update, err := repo.GetApplicant(ctx, applicant.ID)
+	if err != nil {
+		t.Fatalf("GetApplicant: %v", err)
+	}
+	if err := repo.UpdateApplicant(ctx, update); err != nil {
+		t.Fatalf("UpdateApplicant: %v", err)
+	}
+	update.HouseholdMembers[0].Name = "changed"
+	expectMember("changing an updated applicant")
+
+	// A member added to a fetched applicant's spare capacity must not
+	// appear in the stored household either.
+	spare, err := repo.GetApplicant(ctx, applicant.ID)
+	if err != nil {
+		t.Fatalf("GetApplicant: %v", err)
+	}
+	spare.HouseholdMembers = append(spare.HouseholdMembers[:0], models.HouseholdMember{ID: newID(), Name: "changed"})
+	member := models.HouseholdMember{ID: newID(), Name: "Ken Tan", Relation: models.RelationSpouse}
+	if _, err := repo.AddHouseholdMember(ctx, applicant.ID, &member, spare.Version); err != nil {
+		t.Fatalf("AddHouseholdMember: %v", err)
+	}
+	stored, err := repo.GetApplicant(ctx, applicant.ID)
+	if err != nil {
+		t.Fatalf("GetApplicant: %v", err)
+	}
+	if len(stored.HouseholdMembers) != 2 || stored.HouseholdMembers[0].Name != "Gwen Tan" || stored.HouseholdMembers[1].Name != "Ken Tan" {
+		t.Errorf("stored household is %+v, want Gwen and Ken Tan", stored.HouseholdMembers)
+	}
+}
+
+// TestSchemeIsolation checks that schemes handed to or returned by the store
+// share neither benefits nor criteria with the stored copy.
+func TestSchemeIsolation(t *testing.T) {
+	ctx := context.Background()
+	repo := NewSchemeRepo(NewStore())
+	maxAge := 60
+	scheme := &models.Scheme{
+		ID:       newID(),
+		Name:     "Universal Grant",
+		Criteria: models.Criteria{MaxApplicantAge: &maxAge},
+		Benefits: []models.Benefit{{ID: newID(), Name: "Cash", Amount: 500}},
+	}
+	if err := repo.CreateScheme(ctx, scheme); err != nil {
+		t.Fatalf("CreateScheme: %v", err)
+	}
+	expectScheme := func(when string) {
+		t.Helper()
+		stored, err := repo.GetScheme(ctx, scheme.ID)
+		if err != nil {
+			t.Fatalf("GetScheme: %v", err)
+		}
+		if len(stored.Benefits) != 1 || stored.Benefits[0].Amount != 500 ||
+			stored.Criteria.MaxApplicantAge == nil || *stored.Criteria.MaxApplicantAge != 60 {
+			t.Errorf("after %s the stored scheme has benefits %+v and maximum age %v, want 500 cash up to 60",
+				when, stored.Benefits, stored.Criteria.MaxApplicantAge)
+		}
+	}
+
+	scheme.Benefits[0].Amount = 1
+	maxAge = 1
+	expectScheme("changing the created scheme")
+
+	got, err := repo.GetScheme(ctx, scheme.ID)
+	if err != nil {
+		t.Fatalf("GetScheme: %v", err)
+	}
+	got.Benefits[0].Amount = 1
+	*got.Criteria.MaxApplicantAge = 1
+	expectScheme("changing a fetched scheme")
+
+	listed, _, err := repo.GetAllSchemes(ctx, repository.SchemeFilter{})
+	if err != nil {
+		t.Fatalf("GetAllSchemes: %v", err)
+	}
+	listed[0].Benefits[0].Amount = 1
+	*listed[0].Criteria.MaxApplicantAge = 1
+	expectScheme("changing a listed scheme")
+
+	update, err := repo.GetScheme(ctx, scheme.ID)
+	if err != nil {
+		t.Fatalf("GetScheme: %v", err)
+	}
+	if err := repo.UpdateScheme(ctx, update); err != nil {
+		t.Fatalf("UpdateScheme: %v", err)
+	}
+	update.Benefits[0].Amount = 1
+	*update.Criteria.MaxApplicantAge = 1
+	expectScheme("changing an updated scheme")
+
+	criteria := models.Criteria{MaxApplicantAge: &maxAge}
+	maxAge = 60
+	if _, err := repo.UpdateCriteria(ctx, scheme.ID, &criteria, 2); err != nil {
+		t.Fatalf("UpdateCriteria: %v", err)
+	}
+	maxAge = 1
+	expectScheme("changing the criteria set")
+}
+
+// TestApplicationEventIsolation checks that the history returned for an
+// application shares no memory with the stored events.
+func TestApplicationEventIsolation(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	applicantID, schemeID := newID(), newID()
+	store.applicants[applicantID] = models.Applicant{ID: applicantID}
+	store.schemes[schemeID] = models.Scheme{ID: schemeID}
+	repo := NewApplicationRepo(store)
+
+	application := &models.Application{ID: newID(), ApplicantID: applicantID, SchemeID: schemeID, Status: models.StatusDraft}
+	event := &models.ApplicationEvent{ID: newID(), ApplicationID: application.ID, ToStatus: models.StatusDraft, Actor: "user:alice"}
+	if err := repo.CreateApplication(ctx, application, event); err != nil {
+		t.Fatalf("CreateApplication: %v", err)
+	}
+	event.Actor = "changed"
+	application.Status = models.StatusApproved
+
+	events, err := repo.GetApplicationEvents(ctx, application.ID)
+	if err != nil {
+		t.Fatalf("GetApplicationEvents: %v", err)
+	}
+	events[0].Actor = "changed"
+	_ = append(events[:1], models.ApplicationEvent{Actor: "appended"})
+
+	stored, err := repo.GetApplicationEvents(ctx, application.ID)
+	if err != nil {
+		t.Fatalf("GetApplicationEvents: %v", err)
+	}
+	if len(stored) != 1 || stored[0].Actor != "user:alice" {
+		t.Errorf("stored history is %+v, want the creation by user:alice alone", stored)
+	}
+	got, err := repo.GetApplication(ctx, application.ID)
+	if err != nil {
+		t.Fatalf("GetApplication: %v", err)
+	}
+	got.Status = models.StatusRejected
+	if got, _ := repo.GetApplication(ctx, application.ID); got.Status != models.StatusDraft {
+		t.Errorf("stored application is %s, want draft", got.Status)
+	}
+}
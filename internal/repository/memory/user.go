@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type UserRepo struct {
+	store *Store
+}
+
+func NewUserRepo(store *Store) *UserRepo {
+	return &UserRepo{store: store}
+}
+
+func (r *UserRepo) CreateUser(ctx context.Context, user *models.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.users[user.ID]; exists {
+		return repository.ErrAlreadyExists
+	}
+	for _, existing := range r.store.users {
+		if existing.Username == user.Username {
+			return repository.ErrUsernameTaken
+		}
+	}
+
+	r.store.users[user.ID] = *user
+	return nil
+}
+
+func (r *UserRepo) GetUser(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+	return &user, nil
+}
+
+func (r *UserRepo) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if user.Username == username {
+			return &user, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (r *UserRepo) UpdateUser(ctx context.Context, user *models.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.users[user.ID]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+
+	stored.PasswordHash = user.PasswordHash
+	stored.Role = user.Role
+	stored.Disabled = user.Disabled
+	r.store.users[user.ID] = stored
+	return nil
+}
@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKeyRepo struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepo(db *sql.DB) *APIKeyRepo {
+	return &APIKeyRepo{db: db}
+}
+
+func (r *APIKeyRepo) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	query := `
+        INSERT INTO api_keys (id, name, prefix, key_hash, role, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+    `
+	_, err := r.db.ExecContext(ctx, query,
+		key.ID,
+		key.Name,
+		key.Prefix,
+		key.Hash,
+		key.Role,
+		key.CreatedAt,
+	)
+	if isUniqueViolation(err, "api_keys_pkey") || isUniqueViolation(err, "api_keys_key_hash_key") {
+		return repository.ErrAlreadyExists
+	}
+	return err
+}
+
+const apiKeyColumns = `
+        SELECT id, name, prefix, key_hash, role, created_at, revoked_at
+        FROM api_keys`
+
+func (r *APIKeyRepo) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	keys, err := r.queryAPIKeys(ctx, apiKeyColumns+"\n        WHERE key_hash = $1", hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, repository.ErrAPIKeyNotFound
+	}
+	return &keys[0], nil
+}
+
+func (r *APIKeyRepo) GetAllAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	return r.queryAPIKeys(ctx, apiKeyColumns+"\n        ORDER BY created_at, id")
+}
+
+func (r *APIKeyRepo) queryAPIKeys(ctx context.Context, query string, args ...any) ([]models.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []models.APIKey
+	for rows.Next() {
+		var key models.APIKey
+		var revokedAt sql.NullTime
+		if err := rows.Scan(
+			&key.ID,
+			&key.Name,
+			&key.Prefix,
+			&key.Hash,
+			&key.Role,
+			&key.CreatedAt,
+			&revokedAt,
+		); err != nil {
+			return nil, err
+		}
+		if revokedAt.Valid {
+			key.RevokedAt = &revokedAt.Time
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *APIKeyRepo) RevokeAPIKey(ctx context.Context, id uuid.UUID, at time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $2) WHERE id = $1`,
+		id, at,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result, repository.ErrAPIKeyNotFound)
+}
@@ -3,20 +3,31 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"financial_assistance/internal/encryption"
 	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type ApplicantRepo struct {
-	db *sql.DB
+	db     *sql.DB
+	sealer fieldSealer
 }
 
-func NewApplicantRepo(db *sql.DB) *ApplicantRepo {
-	return &ApplicantRepo{db: db}
+// NewApplicantRepo returns a repository that seals personal data with the
+// active key of keyring.
+func NewApplicantRepo(db *sql.DB, keyring *encryption.Keyring) *ApplicantRepo {
+	return &ApplicantRepo{db: db, sealer: fieldSealer{keyring: keyring}}
 }
 
 func (r *ApplicantRepo) CreateApplicant(ctx context.Context, applicant *models.Applicant) error {
+	sealed, err := r.sealer.sealApplicant(applicant)
+	if err != nil {
+		return err
+	}
+
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -24,53 +35,108 @@ func (r *ApplicantRepo) CreateApplicant(ctx context.Context, applicant *models.A
 	defer tx.Rollback()
 
 	query := `
-        INSERT INTO applicants (id, name, employment_status, marital_status, sex, date_of_birth)
-        VALUES ($1, $2, $3, $4, $5, $6)
+        INSERT INTO applicants (id, encrypted_name, encrypted_date_of_birth, encrypted_national_id,
+            national_id_index, name_dob_index, key_id, employment_status, marital_status, sex, monthly_income)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
     `
 	_, err = tx.ExecContext(ctx, query,
 		applicant.ID,
-		applicant.Name,
+		sealed.name,
+		sealed.dateOfBirth,
+		nullBytes(sealed.nationalID),
+		nullBytes(sealed.nationalIDIndex),
+		sealed.nameDOBIndex,
+		sealed.keyID,
 		applicant.EmploymentStatus,
 		applicant.MaritalStatus,
 		applicant.Sex,
-		applicant.DateOfBirth,
+		applicant.MonthlyIncome,
 	)
+	if isUniqueViolation(err, "applicants_pkey") {
+		return repository.ErrAlreadyExists
+	}
 	if err != nil {
 		return err
 	}
 
-	if len(applicant.HouseholdMembers) > 0 {
-		memberQuery := `
-            INSERT INTO household_members (id, name, employment_status, sex, date_of_birth, relation, school_level, applicant_id)
-            VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-        `
-		for _, member := range applicant.HouseholdMembers {
-			_, err = tx.ExecContext(ctx, memberQuery,
-				member.ID,
-				member.Name,
-				member.EmploymentStatus,
-				member.Sex,
-				member.DateOfBirth,
-				member.Relation,
-				member.SchoolLevel,
-				applicant.ID,
-			)
-			if err != nil {
-				return err
-			}
+	for i := range applicant.HouseholdMembers {
+		if err := r.insertHouseholdMember(ctx, tx, applicant.ID, &applicant.HouseholdMembers[i]); err != nil {
+			return err
 		}
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	applicant.Version = 1
+	return nil
 }
 
-func (r *ApplicantRepo) GetAllApplicants(ctx context.Context) ([]models.Applicant, error) {
-	query := `
-        SELECT id, name, employment_status, marital_status, sex, date_of_birth 
+func (r *ApplicantRepo) GetAllApplicants(ctx context.Context, filter repository.ApplicantFilter) ([]models.Applicant, string, error) {
+	page, err := filter.Page.Resolve(repository.ApplicantSortFields)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var q listQuery
+	if filter.EmploymentStatus != "" {
+		q.where("employment_status = ?", filter.EmploymentStatus)
+	}
+	if filter.MaritalStatus != "" {
+		q.where("marital_status = ?", filter.MaritalStatus)
+	}
+	if filter.NationalID != "" {
+		q.where("national_id_index = ?", r.sealer.nationalIDIndex(filter.NationalID))
+	}
+	if filter.Name != "" {
+		q.where("name_dob_index = ?", r.sealer.nameDOBIndex(filter.Name, filter.DateOfBirth))
+	}
+
+	query, args := q.build(applicantColumns+`
+        FROM applicants`, page.Field, "id", page)
+
+	applicants, err := r.queryApplicants(ctx, query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	applicants, next := repository.TrimPage(page, applicants, repository.ApplicantSortValue,
+		func(a *models.Applicant) uuid.UUID { return a.ID })
+
+	if err := r.loadHouseholds(ctx, applicants); err != nil {
+		return nil, "", err
+	}
+
+	return applicants, next, nil
+}
+
+func (r *ApplicantRepo) GetApplicant(ctx context.Context, id uuid.UUID) (*models.Applicant, error) {
+	query := applicantColumns + `
         FROM applicants
+        WHERE id = $1
     `
 
-	rows, err := r.db.QueryContext(ctx, query)
+	applicants, err := r.queryApplicants(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(applicants) == 0 {
+		return nil, repository.ErrApplicantNotFound
+	}
+
+	if err := r.loadHouseholds(ctx, applicants); err != nil {
+		return nil, err
+	}
+
+	return &applicants[0], nil
+}
+
+const applicantColumns = `
+        SELECT id, name, date_of_birth, encrypted_name, encrypted_date_of_birth, encrypted_national_id, key_id,
+            employment_status, marital_status, sex, monthly_income, version`
+
+func (r *ApplicantRepo) queryApplicants(ctx context.Context, query string, args ...any) ([]models.Applicant, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -79,104 +145,303 @@ func (r *ApplicantRepo) GetAllApplicants(ctx context.Context) ([]models.Applican
 	var applicants []models.Applicant
 	for rows.Next() {
 		var app models.Applicant
+		var pii piiColumns
 		if err := rows.Scan(
 			&app.ID,
-			&app.Name,
+			&pii.name,
+			&pii.dateOfBirth,
+			&pii.sealedName,
+			&pii.sealedDateOfBirth,
+			&pii.sealedNationalID,
+			&pii.keyID,
 			&app.EmploymentStatus,
 			&app.MaritalStatus,
 			&app.Sex,
-			&app.DateOfBirth,
+			&app.MonthlyIncome,
+			&app.Version,
 		); err != nil {
 			return nil, err
 		}
-
-		membersQuery := `
-            SELECT id, name, employment_status, sex, date_of_birth, relation, school_level
-            FROM household_members
-            WHERE applicant_id = $1
-        `
-
-		memberRows, err := r.db.QueryContext(ctx, membersQuery, app.ID)
-		if err != nil {
+		if err := r.sealer.openApplicant(&app, &pii); err != nil {
 			return nil, err
 		}
-		defer memberRows.Close()
-
-		var members []models.HouseholdMember
-		for memberRows.Next() {
-			var member models.HouseholdMember
-			if err := memberRows.Scan(
-				&member.ID,
-				&member.Name,
-				&member.EmploymentStatus,
-				&member.Sex,
-				&member.DateOfBirth,
-				&member.Relation,
-				&member.SchoolLevel,
-			); err != nil {
-				return nil, err
-			}
-			member.ApplicantID = app.ID
-			members = append(members, member)
-		}
-
-		app.HouseholdMembers = members
 		applicants = append(applicants, app)
 	}
 
-	return applicants, nil
+	return applicants, rows.Err()
 }
 
-func (r *ApplicantRepo) GetApplicant(ctx context.Context, id uuid.UUID) (*models.Applicant, error) {
-	query := `
-        SELECT id, name, employment_status, marital_status, sex, date_of_birth 
-        FROM applicants 
-        WHERE id = $1
-    `
+// loadHouseholds fills in the household members of all given applicants with
+// a single query.
+func (r *ApplicantRepo) loadHouseholds(ctx context.Context, applicants []models.Applicant) error {
+	if len(applicants) == 0 {
+		return nil
+	}
 
-	var app models.Applicant
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&app.ID,
-		&app.Name,
-		&app.EmploymentStatus,
-		&app.MaritalStatus,
-		&app.Sex,
-		&app.DateOfBirth,
-	)
-	if err != nil {
-		return nil, err
+	ids := make([]string, len(applicants))
+	index := make(map[uuid.UUID]int, len(applicants))
+	for i, app := range applicants {
+		ids[i] = app.ID.String()
+		index[app.ID] = i
 	}
 
-	membersQuery := `
-        SELECT id, name, employment_status, sex, date_of_birth, relation, school_level
+	query := `
+        SELECT id, name, date_of_birth, encrypted_name, encrypted_date_of_birth, key_id,
+            employment_status, sex, relation, school_level, monthly_income, applicant_id
         FROM household_members
-        WHERE applicant_id = $1
+        WHERE applicant_id = ANY($1::uuid[])
+        ORDER BY applicant_id, id
     `
 
-	rows, err := r.db.QueryContext(ctx, membersQuery, id)
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var members []models.HouseholdMember
 	for rows.Next() {
 		var member models.HouseholdMember
+		var pii piiColumns
 		if err := rows.Scan(
 			&member.ID,
-			&member.Name,
+			&pii.name,
+			&pii.dateOfBirth,
+			&pii.sealedName,
+			&pii.sealedDateOfBirth,
+			&pii.keyID,
 			&member.EmploymentStatus,
 			&member.Sex,
-			&member.DateOfBirth,
 			&member.Relation,
 			&member.SchoolLevel,
+			&member.MonthlyIncome,
+			&member.ApplicantID,
 		); err != nil {
-			return nil, err
+			return err
 		}
-		member.ApplicantID = id
-		members = append(members, member)
+		if err := r.sealer.openMember(&member, &pii); err != nil {
+			return err
+		}
+
+		app := &applicants[index[member.ApplicantID]]
+		app.HouseholdMembers = append(app.HouseholdMembers, member)
+	}
+
+	return rows.Err()
+}
+
+func (r *ApplicantRepo) UpdateApplicant(ctx context.Context, applicant *models.Applicant) error {
+	sealed, err := r.sealer.sealApplicant(applicant)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	version, err := bumpApplicantVersion(ctx, tx, applicant.ID, applicant.Version)
+	if err != nil {
+		return err
+	}
+
+	query := `
+        UPDATE applicants
+        SET name = NULL, date_of_birth = NULL, encrypted_name = $2, encrypted_date_of_birth = $3,
+            encrypted_national_id = $4, national_id_index = $5, name_dob_index = $6, key_id = $7,
+            employment_status = $8, marital_status = $9, sex = $10, monthly_income = $11
+        WHERE id = $1
+    `
+	_, err = tx.ExecContext(ctx, query,
+		applicant.ID,
+		sealed.name,
+		sealed.dateOfBirth,
+		nullBytes(sealed.nationalID),
+		nullBytes(sealed.nationalIDIndex),
+		sealed.nameDOBIndex,
+		sealed.keyID,
+		applicant.EmploymentStatus,
+		applicant.MaritalStatus,
+		applicant.Sex,
+		applicant.MonthlyIncome,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM household_members WHERE applicant_id = $1`, applicant.ID)
+	if err != nil {
+		return err
+	}
+
+	for i := range applicant.HouseholdMembers {
+		if err := r.insertHouseholdMember(ctx, tx, applicant.ID, &applicant.HouseholdMembers[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	applicant.Version = version
+	return nil
+}
+
+func (r *ApplicantRepo) DeleteApplicant(ctx context.Context, id uuid.UUID, version int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := bumpApplicantVersion(ctx, tx, id, version); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM household_members WHERE applicant_id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM applicants WHERE id = $1`, id)
+	if isForeignKeyViolation(err) {
+		return repository.ErrReferenced
+	}
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(result, repository.ErrApplicantNotFound); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *ApplicantRepo) AddHouseholdMember(ctx context.Context, applicantID uuid.UUID, member *models.HouseholdMember, version int) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	version, err = bumpApplicantVersion(ctx, tx, applicantID, version)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := r.insertHouseholdMember(ctx, tx, applicantID, member); err != nil {
+		return 0, err
+	}
+
+	return version, tx.Commit()
+}
+
+func (r *ApplicantRepo) UpdateHouseholdMember(ctx context.Context, applicantID uuid.UUID, member *models.HouseholdMember, version int) (int, error) {
+	sealed, err := r.sealer.sealMember(member)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	version, err = bumpApplicantVersion(ctx, tx, applicantID, version)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `
+        UPDATE household_members
+        SET name = NULL, date_of_birth = NULL, encrypted_name = $3, encrypted_date_of_birth = $4, key_id = $5,
+            employment_status = $6, sex = $7, relation = $8, school_level = $9, monthly_income = $10
+        WHERE id = $1 AND applicant_id = $2
+    `
+	result, err := tx.ExecContext(ctx, query,
+		member.ID,
+		applicantID,
+		sealed.name,
+		sealed.dateOfBirth,
+		sealed.keyID,
+		member.EmploymentStatus,
+		member.Sex,
+		member.Relation,
+		member.SchoolLevel,
+		member.MonthlyIncome,
+	)
+	if err != nil {
+		return 0, err
+	}
+	if err := expectAffected(result, repository.ErrHouseholdMemberNotFound); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	member.ApplicantID = applicantID
+	return version, nil
+}
+
+func (r *ApplicantRepo) DeleteHouseholdMember(ctx context.Context, applicantID, memberID uuid.UUID, version int) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	version, err = bumpApplicantVersion(ctx, tx, applicantID, version)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx,
+		`DELETE FROM household_members WHERE id = $1 AND applicant_id = $2`,
+		memberID, applicantID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	if err := expectAffected(result, repository.ErrHouseholdMemberNotFound); err != nil {
+		return 0, err
+	}
+
+	return version, tx.Commit()
+}
+
+func (r *ApplicantRepo) insertHouseholdMember(ctx context.Context, tx *sql.Tx, applicantID uuid.UUID, member *models.HouseholdMember) error {
+	sealed, err := r.sealer.sealMember(member)
+	if err != nil {
+		return err
+	}
+
+	query := `
+        INSERT INTO household_members (id, encrypted_name, encrypted_date_of_birth, key_id,
+            employment_status, sex, relation, school_level, monthly_income, applicant_id)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+    `
+	_, err = tx.ExecContext(ctx, query,
+		member.ID,
+		sealed.name,
+		sealed.dateOfBirth,
+		sealed.keyID,
+		member.EmploymentStatus,
+		member.Sex,
+		member.Relation,
+		member.SchoolLevel,
+		member.MonthlyIncome,
+		applicantID,
+	)
+	if isUniqueViolation(err, "household_members_pkey") {
+		return repository.ErrAlreadyExists
+	}
+	if err != nil {
+		return err
 	}
 
-	app.HouseholdMembers = members
-	return &app, nil
+	member.ApplicantID = applicantID
+	return nil
 }
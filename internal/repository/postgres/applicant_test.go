@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository"
+	"financial_assistance/internal/repository/repotest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestApplicantLookup(t *testing.T) {
+	repotest.ApplicantLookup(t, NewApplicantRepo(openTestDB(t), testKeyring(t, "test-1")))
+}
+
+func TestApplicantFilters(t *testing.T) {
+	repotest.ApplicantFilters(t, NewApplicantRepo(openTestDB(t), testKeyring(t, "test-1")))
+}
+
+// TestApplicantLookupAfterRotation checks that blind indexes, which do not
+// depend on the active key, still find applicants sealed before a rotation.
+func TestApplicantLookupAfterRotation(t *testing.T) {
+	db := openTestDB(t)
+	before := NewApplicantRepo(db, testKeyring(t, "test-1"))
+	after := NewApplicantRepo(db, testKeyring(t, "test-2"))
+
+	applicant := newTestApplicant()
+	if err := before.CreateApplicant(context.Background(), &applicant); err != nil {
+		t.Fatalf("CreateApplicant: %v", err)
+	}
+	found, _, err := after.GetAllApplicants(context.Background(), repository.ApplicantFilter{NationalID: applicant.NationalID})
+	if err != nil {
+		t.Fatalf("GetAllApplicants: %v", err)
+	}
+	if len(found) != 1 || found[0].ID != applicant.ID || found[0].Name != applicant.Name {
+		t.Errorf("found %v, want applicant %s", found, applicant.ID)
+	}
+}
+
+func TestReencrypt(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	before := NewApplicantRepo(db, testKeyring(t, "test-1"))
+
+	sealed := newTestApplicant()
+	if err := before.CreateApplicant(ctx, &sealed); err != nil {
+		t.Fatalf("CreateApplicant: %v", err)
+	}
+	// Rows written before encryption was introduced, which had no national ID.
+	plain := newTestApplicant()
+	plain.NationalID = ""
+	member := plain.HouseholdMembers[0]
+	_, err := db.ExecContext(ctx, `
+        INSERT INTO applicants (id, name, date_of_birth, employment_status, marital_status, sex)
+        VALUES ($1, $2, $3, $4, $5, $6)
+    `, plain.ID, plain.Name, plain.DateOfBirth, plain.EmploymentStatus, plain.MaritalStatus, plain.Sex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.ExecContext(ctx, `
+        INSERT INTO household_members (id, name, sex, date_of_birth, relation, applicant_id)
+        VALUES ($1, $2, $3, $4, $5, $6)
+    `, member.ID, member.Name, member.Sex, member.DateOfBirth, member.Relation, plain.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	after := NewApplicantRepo(db, testKeyring(t, "test-2"))
+	for {
+		n, remaining, err := after.Reencrypt(ctx, 50)
+		if err != nil {
+			t.Fatalf("Reencrypt: %v", err)
+		}
+		if remaining == 0 {
+			break
+		}
+		if n == 0 {
+			t.Fatalf("Reencrypt changed nothing with %d rows remaining and none locked", remaining)
+		}
+	}
+
+	usage, err := after.KeyUsage(ctx)
+	if err != nil {
+		t.Fatalf("KeyUsage: %v", err)
+	}
+	for _, u := range usage {
+		if u.KeyID != "test-2" {
+			t.Errorf("%d %s rows still under key %q", u.Rows, u.Table, u.KeyID)
+		}
+	}
+
+	for _, want := range []models.Applicant{sealed, plain} {
+		var keyID string
+		var name sql.NullString
+		err := db.QueryRowContext(ctx, `SELECT key_id, name FROM applicants WHERE id = $1`, want.ID).Scan(&keyID, &name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if keyID != "test-2" || name.Valid {
+			t.Errorf("applicant %s: key %q, plaintext name %v; want key test-2 and no plaintext", want.ID, keyID, name.Valid)
+		}
+
+		got, err := after.GetApplicant(ctx, want.ID)
+		if err != nil {
+			t.Fatalf("GetApplicant: %v", err)
+		}
+		if got.Name != want.Name || !got.DateOfBirth.Equal(want.DateOfBirth) || got.NationalID != want.NationalID {
+			t.Errorf("after rotation got %q born %v (%q), want %q born %v (%q)",
+				got.Name, got.DateOfBirth, got.NationalID, want.Name, want.DateOfBirth, want.NationalID)
+		}
+		if got.Version != 1 {
+			t.Errorf("re-encryption changed the version to %d", got.Version)
+		}
+		if len(got.HouseholdMembers) != 1 || got.HouseholdMembers[0].Name != "Gwen Tan" ||
+			!got.HouseholdMembers[0].DateOfBirth.Equal(member.DateOfBirth) {
+			t.Errorf("after rotation got household %v, want %s", got.HouseholdMembers, member.Name)
+		}
+	}
+
+	found, _, err := after.GetAllApplicants(ctx, repository.ApplicantFilter{
+		Name: models.NormalizeName(plain.Name), DateOfBirth: plain.DateOfBirth,
+	})
+	if err != nil {
+		t.Fatalf("GetAllApplicants: %v", err)
+	}
+	if len(found) != 1 || found[0].ID != plain.ID {
+		t.Errorf("lookup of a re-encrypted plaintext row found %v, want %s", found, plain.ID)
+	}
+}
+
+func newTestApplicant() models.Applicant {
+	id := uuid.Must(uuid.NewV7())
+	return models.Applicant{
+		ID:               id,
+		Name:             "Mary Tan " + id.String()[24:],
+		NationalID:       "S" + id.String()[28:],
+		EmploymentStatus: models.EmploymentUnemployed,
+		MaritalStatus:    models.MaritalMarried,
+		Sex:              models.SexFemale,
+		DateOfBirth:      time.Date(1985, time.March, 14, 0, 0, 0, 0, time.UTC),
+		HouseholdMembers: []models.HouseholdMember{{
+			ID: uuid.Must(uuid.NewV7()), Name: "Gwen Tan", Sex: models.SexFemale, Relation: models.RelationDaughter,
+			DateOfBirth: time.Date(2016, time.February, 1, 0, 0, 0, 0, time.UTC),
+		}},
+	}
+}
+
+func TestHouseholdMembers(t *testing.T) {
+	repotest.HouseholdMembers(t, NewApplicantRepo(openTestDB(t), testKeyring(t, "test-1")))
+}
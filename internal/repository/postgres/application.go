@@ -3,7 +3,9 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository"
 
 	"github.com/google/uuid"
 )
@@ -16,24 +18,50 @@ func NewApplicationRepo(db *sql.DB) *ApplicationRepo {
 	return &ApplicationRepo{db: db}
 }
 
-func (r *ApplicationRepo) CreateApplication(ctx context.Context, application *models.Application) error {
+func (r *ApplicationRepo) CreateApplication(ctx context.Context, application *models.Application, event *models.ApplicationEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
-        INSERT INTO applications (application_id, applicant_id, scheme_id, status)
-        VALUES ($1, $2, $3, $4)
+        INSERT INTO applications (application_id, applicant_id, scheme_id, status, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
     `
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = tx.ExecContext(ctx, query,
 		application.ID,
 		application.ApplicantID,
 		application.SchemeID,
 		application.Status,
+		application.CreatedAt,
+		application.UpdatedAt,
 	)
-	return err
+	if isUniqueViolation(err, "applications_active_applicant_scheme_key") {
+		return repository.ErrActiveApplicationExists
+	}
+	if isUniqueViolation(err, "applications_pkey") {
+		return repository.ErrAlreadyExists
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := insertApplicationEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	application.Version = 1
+	return nil
 }
 
 func (r *ApplicationRepo) GetApplication(ctx context.Context, id uuid.UUID) (*models.Application, error) {
 	query := `
-        SELECT application_id, applicant_id, scheme_id, status
+        SELECT application_id, applicant_id, scheme_id, status, created_at, updated_at, version
         FROM applications
         WHERE application_id = $1
     `
@@ -44,7 +72,13 @@ func (r *ApplicationRepo) GetApplication(ctx context.Context, id uuid.UUID) (*mo
 		&app.ApplicantID,
 		&app.SchemeID,
 		&app.Status,
+		&app.CreatedAt,
+		&app.UpdatedAt,
+		&app.Version,
 	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrApplicationNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -52,13 +86,73 @@ func (r *ApplicationRepo) GetApplication(ctx context.Context, id uuid.UUID) (*mo
 	return &app, nil
 }
 
-func (r *ApplicationRepo) GetAllApplications(ctx context.Context) ([]models.Application, error) {
+func (r *ApplicationRepo) GetAllApplications(ctx context.Context, filter repository.ApplicationFilter) ([]models.Application, string, error) {
+	page, err := filter.Page.Resolve(repository.ApplicationSortFields)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var q listQuery
+	if filter.Status != "" {
+		q.where("status = ?", filter.Status)
+	}
+	if filter.ApplicantID != uuid.Nil {
+		q.where("applicant_id = ?", filter.ApplicantID)
+	}
+	if filter.SchemeID != uuid.Nil {
+		q.where("scheme_id = ?", filter.SchemeID)
+	}
+
+	sortColumn := page.Field
+	if sortColumn == "id" {
+		sortColumn = "application_id"
+	}
+
+	query, args := q.build(`
+        SELECT application_id, applicant_id, scheme_id, status, created_at, updated_at, version
+        FROM applications`, sortColumn, "application_id", page)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var applications []models.Application
+	for rows.Next() {
+		var app models.Application
+		if err := rows.Scan(
+			&app.ID,
+			&app.ApplicantID,
+			&app.SchemeID,
+			&app.Status,
+			&app.CreatedAt,
+			&app.UpdatedAt,
+			&app.Version,
+		); err != nil {
+			return nil, "", err
+		}
+		applications = append(applications, app)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	applications, next := repository.TrimPage(page, applications, repository.ApplicationSortValue,
+		func(a *models.Application) uuid.UUID { return a.ID })
+	return applications, next, nil
+}
+
+func (r *ApplicationRepo) GetApplicationsByApplicantAndScheme(ctx context.Context, applicantID, schemeID uuid.UUID) ([]models.Application, error) {
 	query := `
-        SELECT application_id, applicant_id, scheme_id, status
+        SELECT application_id, applicant_id, scheme_id, status, created_at, updated_at, version
         FROM applications
+        WHERE applicant_id = $1 AND scheme_id = $2
+        ORDER BY created_at
     `
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.db.QueryContext(ctx, query, applicantID, schemeID)
 	if err != nil {
 		return nil, err
 	}
@@ -72,11 +166,107 @@ func (r *ApplicationRepo) GetAllApplications(ctx context.Context) ([]models.Appl
 			&app.ApplicantID,
 			&app.SchemeID,
 			&app.Status,
+			&app.CreatedAt,
+			&app.UpdatedAt,
+			&app.Version,
 		); err != nil {
 			return nil, err
 		}
 		applications = append(applications, app)
 	}
 
-	return applications, nil
+	return applications, rows.Err()
+}
+
+func (r *ApplicationRepo) UpdateApplicationStatus(ctx context.Context, event *models.ApplicationEvent, version int) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	version, err = bumpVersion(ctx, tx, "applications", "application_id", event.ApplicationID, version, repository.ErrApplicationNotFound)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `
+        UPDATE applications
+        SET status = $3, updated_at = $4
+        WHERE application_id = $1 AND status = $2
+    `
+
+	result, err := tx.ExecContext(ctx, query,
+		event.ApplicationID,
+		event.FromStatus,
+		event.ToStatus,
+		event.CreatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	if err := expectAffected(result, repository.ErrStatusChanged); err != nil {
+		return 0, err
+	}
+
+	if err := insertApplicationEvent(ctx, tx, event); err != nil {
+		return 0, err
+	}
+
+	return version, tx.Commit()
+}
+
+func (r *ApplicationRepo) GetApplicationEvents(ctx context.Context, applicationID uuid.UUID) ([]models.ApplicationEvent, error) {
+	query := `
+        SELECT id, application_id, from_status, to_status, actor, reason_code, created_at
+        FROM application_events
+        WHERE application_id = $1
+        ORDER BY created_at, id
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.ApplicationEvent
+	for rows.Next() {
+		var event models.ApplicationEvent
+		var fromStatus, reasonCode sql.NullString
+		if err := rows.Scan(
+			&event.ID,
+			&event.ApplicationID,
+			&fromStatus,
+			&event.ToStatus,
+			&event.Actor,
+			&reasonCode,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		event.FromStatus = models.ApplicationStatus(fromStatus.String)
+		event.ReasonCode = reasonCode.String
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func insertApplicationEvent(ctx context.Context, tx *sql.Tx, event *models.ApplicationEvent) error {
+	query := `
+        INSERT INTO application_events (id, application_id, from_status, to_status, actor, reason_code, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+    `
+
+	_, err := tx.ExecContext(ctx, query,
+		event.ID,
+		event.ApplicationID,
+		sql.NullString{String: string(event.FromStatus), Valid: event.FromStatus != ""},
+		event.ToStatus,
+		event.Actor,
+		sql.NullString{String: event.ReasonCode, Valid: event.ReasonCode != ""},
+		event.CreatedAt,
+	)
+	return err
 }
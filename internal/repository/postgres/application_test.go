@@ -0,0 +1,21 @@
+package postgres
+
+import (
+	"financial_assistance/internal/repository/repotest"
+	"testing"
+)
+
+func TestApplicationPages(t *testing.T) {
+	db := openTestDB(t)
+	repotest.ApplicationPages(t, NewApplicantRepo(db, testKeyring(t, "test-1")), NewSchemeRepo(db), NewApplicationRepo(db))
+}
+
+func TestApplicationHistory(t *testing.T) {
+	db := openTestDB(t)
+	repotest.ApplicationHistory(t, NewApplicantRepo(db, testKeyring(t, "test-1")), NewSchemeRepo(db), NewApplicationRepo(db))
+}
+
+func TestApplicationCRUD(t *testing.T) {
+	db := openTestDB(t)
+	repotest.ApplicationCRUD(t, NewApplicantRepo(db, testKeyring(t, "test-1")), NewSchemeRepo(db), NewApplicationRepo(db))
+}
@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"financial_assistance/internal/encryption"
+	"financial_assistance/internal/migrations"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// testDSNVariable names the environment variable holding the connection
+// string of a database the tests may write to, such as
+// "postgres://postgres@localhost:5433/financial_assistance_test?sslmode=disable".
+// Tests that need PostgreSQL are skipped when it is not set.
+const testDSNVariable = "TEST_DATABASE_DSN"
+
+var migrateOnce struct {
+	sync.Once
+	err error
+}
+
+// openTestDB connects to the test database and brings its schema up to
+// date.
+func openTestDB(t testing.TB) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv(testDSNVariable)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNVariable)
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrateOnce.Do(func() {
+		var migrator *migrations.Migrator
+		migrator, migrateOnce.err = migrations.NewMigrator(db)
+		if migrateOnce.err == nil {
+			_, migrateOnce.err = migrator.Up(context.Background())
+		}
+	})
+	if migrateOnce.err != nil {
+		t.Fatalf("migrating test database: %v", migrateOnce.err)
+	}
+	return db
+}
+
+// testKeys are the keys of the test keyring. They are fixed, and every test
+// keyring holds all of them, so that rows left in the test database by
+// earlier runs can still be opened whichever key sealed them.
+var testKeys = map[string]string{
+	"test-1": base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)),
+	"test-2": base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32)),
+}
+
+var testIndexKey = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{3}, 32))
+
+// testKeyring returns a keyring holding the test keys that seals with the
+// active one.
+func testKeyring(t testing.TB, active string) *encryption.Keyring {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{"active": active, "keys": testKeys, "index_key": testIndexKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keyring, err := encryption.LoadKeyring(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
@@ -0,0 +1,365 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"financial_assistance/internal/encryption"
+	"financial_assistance/internal/models"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Applicant and household member names, dates of birth and national IDs are
+// stored sealed by the keyring. Rows written before encryption was
+// introduced keep them in the plaintext columns, with no key ID, until
+// Reencrypt seals them.
+
+const dateLayout = "2006-01-02"
+
+// Names of the blind indexes, which keep equal inputs to different indexes
+// from hashing alike.
+const (
+	nationalIDIndex = "national_id"
+	nameDOBIndex    = "name_dob"
+)
+
+// piiColumns are the personal data columns of a row as scanned.
+type piiColumns struct {
+	name              sql.NullString
+	dateOfBirth       sql.NullTime
+	sealedName        []byte
+	sealedDateOfBirth []byte
+	sealedNationalID  []byte
+	keyID             sql.NullString
+}
+
+// sealedApplicant holds the values written to the personal data columns of
+// an applicant.
+type sealedApplicant struct {
+	name, dateOfBirth, nationalID []byte
+	nationalIDIndex, nameDOBIndex []byte
+	keyID                         string
+}
+
+type sealedMember struct {
+	name, dateOfBirth []byte
+	keyID             string
+}
+
+type fieldSealer struct {
+	keyring *encryption.Keyring
+}
+
+// additionalData binds a sealed value to its column and row, so that it
+// cannot be copied to another record.
+func additionalData(table, column string, id uuid.UUID) []byte {
+	return []byte(table + "." + column + "/" + id.String())
+}
+
+func (s fieldSealer) seal(table, column string, id uuid.UUID, value string) ([]byte, error) {
+	if value == "" {
+		return nil, nil
+	}
+	return s.keyring.Seal([]byte(value), additionalData(table, column, id))
+}
+
+func (s fieldSealer) open(table, column string, id uuid.UUID, sealed []byte) (string, error) {
+	if sealed == nil {
+		return "", nil
+	}
+	value, err := s.keyring.Open(sealed, additionalData(table, column, id))
+	if err != nil {
+		return "", fmt.Errorf("opening %s.%s of %s: %w", table, column, id, err)
+	}
+	return string(value), nil
+}
+
+func (s fieldSealer) openDate(table, column string, id uuid.UUID, sealed []byte) (time.Time, error) {
+	value, err := s.open(table, column, id, sealed)
+	if err != nil || value == "" {
+		return time.Time{}, err
+	}
+	return time.Parse(dateLayout, value)
+}
+
+func (s fieldSealer) nationalIDIndex(nationalID string) []byte {
+	if nationalID == "" {
+		return nil
+	}
+	return s.keyring.BlindIndex(nationalIDIndex, nationalID)
+}
+
+func (s fieldSealer) nameDOBIndex(name string, dateOfBirth time.Time) []byte {
+	return s.keyring.BlindIndex(nameDOBIndex, models.NormalizeName(name), dateOfBirth.Format(dateLayout))
+}
+
+func (s fieldSealer) sealApplicant(a *models.Applicant) (sealedApplicant, error) {
+	sealed := sealedApplicant{
+		nationalIDIndex: s.nationalIDIndex(a.NationalID),
+		nameDOBIndex:    s.nameDOBIndex(a.Name, a.DateOfBirth),
+		keyID:           s.keyring.ActiveKeyID(),
+	}
+
+	var err error
+	if sealed.name, err = s.seal("applicants", "name", a.ID, a.Name); err != nil {
+		return sealed, err
+	}
+	if sealed.dateOfBirth, err = s.seal("applicants", "date_of_birth", a.ID, a.DateOfBirth.Format(dateLayout)); err != nil {
+		return sealed, err
+	}
+	if sealed.nationalID, err = s.seal("applicants", "national_id", a.ID, a.NationalID); err != nil {
+		return sealed, err
+	}
+	return sealed, nil
+}
+
+func (s fieldSealer) openApplicant(a *models.Applicant, c *piiColumns) error {
+	if !c.keyID.Valid {
+		a.Name, a.DateOfBirth = c.name.String, c.dateOfBirth.Time
+		return nil
+	}
+
+	var err error
+	if a.Name, err = s.open("applicants", "name", a.ID, c.sealedName); err != nil {
+		return err
+	}
+	if a.DateOfBirth, err = s.openDate("applicants", "date_of_birth", a.ID, c.sealedDateOfBirth); err != nil {
+		return err
+	}
+	a.NationalID, err = s.open("applicants", "national_id", a.ID, c.sealedNationalID)
+	return err
+}
+
+func (s fieldSealer) sealMember(m *models.HouseholdMember) (sealedMember, error) {
+	sealed := sealedMember{keyID: s.keyring.ActiveKeyID()}
+
+	var err error
+	if sealed.name, err = s.seal("household_members", "name", m.ID, m.Name); err != nil {
+		return sealed, err
+	}
+	sealed.dateOfBirth, err = s.seal("household_members", "date_of_birth", m.ID, m.DateOfBirth.Format(dateLayout))
+	return sealed, err
+}
+
+func (s fieldSealer) openMember(m *models.HouseholdMember, c *piiColumns) error {
+	if !c.keyID.Valid {
+		m.Name, m.DateOfBirth = c.name.String, c.dateOfBirth.Time
+		return nil
+	}
+
+	var err error
+	if m.Name, err = s.open("household_members", "name", m.ID, c.sealedName); err != nil {
+		return err
+	}
+	m.DateOfBirth, err = s.openDate("household_members", "date_of_birth", m.ID, c.sealedDateOfBirth)
+	return err
+}
+
+// nullBytes passes an absent optional value to the database as NULL.
+func nullBytes(b []byte) any {
+	if b == nil {
+		return nil
+	}
+	return b
+}
+
+// KeyUsage is the number of rows of a table sealed with a key. Rows that are
+// not encrypted yet have an empty KeyID.
+type KeyUsage struct {
+	Table string
+	KeyID string
+	Rows  int
+}
+
+func (r *ApplicantRepo) KeyUsage(ctx context.Context) ([]KeyUsage, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT 'applicants', COALESCE(key_id, ''), count(*) FROM applicants GROUP BY key_id
+        UNION ALL
+        SELECT 'household_members', COALESCE(key_id, ''), count(*) FROM household_members GROUP BY key_id
+        ORDER BY 1, 2
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usage []KeyUsage
+	for rows.Next() {
+		var u KeyUsage
+		if err := rows.Scan(&u.Table, &u.KeyID, &u.Rows); err != nil {
+			return nil, err
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
+
+// Reencrypt seals up to limit applicants and up to limit household members
+// that are stored in plaintext or sealed with a key other than the active
+// one. It returns how many rows it changed and how many are still not sealed
+// with the active key; once none remain, the key rotation is complete and
+// the old key can be removed from the keyring. Rows locked by concurrent
+// updates are skipped, so a call may change nothing while rows remain; they
+// are picked up by a later call. Versions are left alone since the data
+// itself does not change.
+func (r *ApplicantRepo) Reencrypt(ctx context.Context, limit int) (done, remaining int, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	applicants, err := r.reencryptApplicants(ctx, tx, limit)
+	if err != nil {
+		return 0, 0, err
+	}
+	members, err := r.reencryptMembers(ctx, tx, limit)
+	if err != nil {
+		return 0, 0, err
+	}
+	if remaining, err = r.countStale(ctx, tx); err != nil {
+		return 0, 0, err
+	}
+
+	return applicants + members, remaining, tx.Commit()
+}
+
+// countStale counts the rows not sealed with the active key, including the
+// ones locked by other transactions.
+func (r *ApplicantRepo) countStale(ctx context.Context, tx *sql.Tx) (int, error) {
+	var n int
+	err := tx.QueryRowContext(ctx, `
+        SELECT (SELECT count(*) FROM applicants WHERE key_id IS DISTINCT FROM $1)
+             + (SELECT count(*) FROM household_members WHERE key_id IS DISTINCT FROM $1)
+    `, r.sealer.keyring.ActiveKeyID()).Scan(&n)
+	return n, err
+}
+
+func (r *ApplicantRepo) reencryptApplicants(ctx context.Context, tx *sql.Tx, limit int) (int, error) {
+	applicants, err := r.lockStaleApplicants(ctx, tx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range applicants {
+		sealed, err := r.sealer.sealApplicant(&applicants[i])
+		if err != nil {
+			return 0, err
+		}
+		_, err = tx.ExecContext(ctx, `
+            UPDATE applicants
+            SET name = NULL, date_of_birth = NULL, encrypted_name = $2, encrypted_date_of_birth = $3,
+                encrypted_national_id = $4, national_id_index = $5, name_dob_index = $6, key_id = $7
+            WHERE id = $1
+        `, applicants[i].ID, sealed.name, sealed.dateOfBirth, nullBytes(sealed.nationalID),
+			nullBytes(sealed.nationalIDIndex), sealed.nameDOBIndex, sealed.keyID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(applicants), nil
+}
+
+// lockStaleApplicants locks and returns the personal data of up to limit
+// applicants that are not sealed with the active key.
+func (r *ApplicantRepo) lockStaleApplicants(ctx context.Context, tx *sql.Tx, limit int) ([]models.Applicant, error) {
+	rows, err := tx.QueryContext(ctx, `
+        SELECT id, name, date_of_birth, encrypted_name, encrypted_date_of_birth, encrypted_national_id, key_id
+        FROM applicants
+        WHERE key_id IS DISTINCT FROM $1
+        ORDER BY id
+        LIMIT $2
+        FOR UPDATE SKIP LOCKED
+    `, r.sealer.keyring.ActiveKeyID(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var applicants []models.Applicant
+	for rows.Next() {
+		var app models.Applicant
+		var pii piiColumns
+		if err := rows.Scan(
+			&app.ID,
+			&pii.name,
+			&pii.dateOfBirth,
+			&pii.sealedName,
+			&pii.sealedDateOfBirth,
+			&pii.sealedNationalID,
+			&pii.keyID,
+		); err != nil {
+			return nil, err
+		}
+		if err := r.sealer.openApplicant(&app, &pii); err != nil {
+			return nil, err
+		}
+		applicants = append(applicants, app)
+	}
+
+	return applicants, rows.Err()
+}
+
+func (r *ApplicantRepo) reencryptMembers(ctx context.Context, tx *sql.Tx, limit int) (int, error) {
+	members, err := r.lockStaleMembers(ctx, tx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range members {
+		sealed, err := r.sealer.sealMember(&members[i])
+		if err != nil {
+			return 0, err
+		}
+		_, err = tx.ExecContext(ctx, `
+            UPDATE household_members
+            SET name = NULL, date_of_birth = NULL, encrypted_name = $2, encrypted_date_of_birth = $3, key_id = $4
+            WHERE id = $1
+        `, members[i].ID, sealed.name, sealed.dateOfBirth, sealed.keyID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(members), nil
+}
+
+func (r *ApplicantRepo) lockStaleMembers(ctx context.Context, tx *sql.Tx, limit int) ([]models.HouseholdMember, error) {
+	rows, err := tx.QueryContext(ctx, `
+        SELECT id, name, date_of_birth, encrypted_name, encrypted_date_of_birth, key_id
+        FROM household_members
+        WHERE key_id IS DISTINCT FROM $1
+        ORDER BY id
+        LIMIT $2
+        FOR UPDATE SKIP LOCKED
+    `, r.sealer.keyring.ActiveKeyID(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []models.HouseholdMember
+	for rows.Next() {
+		var member models.HouseholdMember
+		var pii piiColumns
+		if err := rows.Scan(
+			&member.ID,
+			&pii.name,
+			&pii.dateOfBirth,
+			&pii.sealedName,
+			&pii.sealedDateOfBirth,
+			&pii.keyID,
+		); err != nil {
+			return nil, err
+		}
+		if err := r.sealer.openMember(&member, &pii); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
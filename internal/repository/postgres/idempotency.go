@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"financial_assistance/internal/models"
+	"time"
+)
+
+type IdempotencyRepo struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepo(db *sql.DB) *IdempotencyRepo {
+	return &IdempotencyRepo{db: db}
+}
+
+func (r *IdempotencyRepo) ReserveIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord) (*models.IdempotencyRecord, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2 AND expires_at <= $3`,
+		record.Scope, record.Key, record.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+        INSERT INTO idempotency_keys (scope, key, fingerprint, created_at, expires_at)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (scope, key) DO NOTHING
+    `
+	result, err := tx.ExecContext(ctx, query,
+		record.Scope,
+		record.Key,
+		record.Fingerprint,
+		record.CreatedAt,
+		record.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 1 {
+		return nil, tx.Commit()
+	}
+
+	query = `
+        SELECT scope, key, fingerprint, status_code, content_type, location, etag, body, created_at, expires_at
+        FROM idempotency_keys
+        WHERE scope = $1 AND key = $2
+    `
+	var (
+		existing    models.IdempotencyRecord
+		statusCode  sql.NullInt64
+		contentType sql.NullString
+		location    sql.NullString
+		etag        sql.NullString
+	)
+	err = tx.QueryRowContext(ctx, query, record.Scope, record.Key).Scan(
+		&existing.Scope,
+		&existing.Key,
+		&existing.Fingerprint,
+		&statusCode,
+		&contentType,
+		&location,
+		&etag,
+		&existing.Body,
+		&existing.CreatedAt,
+		&existing.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	existing.StatusCode = int(statusCode.Int64)
+	existing.ContentType = contentType.String
+	existing.Location = location.String
+	existing.ETag = etag.String
+
+	return &existing, tx.Commit()
+}
+
+func (r *IdempotencyRepo) CompleteIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord) error {
+	query := `
+        UPDATE idempotency_keys
+        SET status_code = $3, content_type = $4, location = $5, etag = $6, body = $7
+        WHERE scope = $1 AND key = $2
+    `
+	_, err := r.db.ExecContext(ctx, query,
+		record.Scope,
+		record.Key,
+		record.StatusCode,
+		record.ContentType,
+		record.Location,
+		record.ETag,
+		record.Body,
+	)
+	return err
+}
+
+func (r *IdempotencyRepo) ReleaseIdempotencyKey(ctx context.Context, scope, key string) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2 AND status_code IS NULL`,
+		scope, key,
+	)
+	return err
+}
+
+func (r *IdempotencyRepo) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
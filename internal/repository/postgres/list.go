@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"financial_assistance/internal/repository"
+	"fmt"
+	"strings"
+)
+
+// listQuery collects the WHERE conditions of a list query. Conditions are
+// written with "?" placeholders, which are numbered as they are added.
+type listQuery struct {
+	conditions []string
+	args       []any
+}
+
+func (q *listQuery) where(condition string, args ...any) {
+	for _, arg := range args {
+		q.args = append(q.args, arg)
+		condition = strings.Replace(condition, "?", fmt.Sprintf("$%d", len(q.args)), 1)
+	}
+	q.conditions = append(q.conditions, condition)
+}
+
+// build completes base with the filters, the keyset condition resuming after
+// page.After, the ordering and the limit. It selects one row more than the
+// page size so callers can tell whether another page follows.
+func (q *listQuery) build(base, sortColumn, idColumn string, page repository.PageQuery) (string, []any) {
+	cmp, dir := ">", "ASC"
+	if page.Desc {
+		cmp, dir = "<", "DESC"
+	}
+
+	order := fmt.Sprintf("%s %s", idColumn, dir)
+	if page.After != nil {
+		if sortColumn == idColumn {
+			q.where(fmt.Sprintf("%s %s ?", idColumn, cmp), page.After.ID)
+		} else {
+			q.where(fmt.Sprintf("(%s, %s) %s (?, ?)", sortColumn, idColumn, cmp), page.After.Value, page.After.ID)
+		}
+	}
+	if sortColumn != idColumn {
+		order = fmt.Sprintf("%s %s, %s", sortColumn, dir, order)
+	}
+
+	query := base
+	if len(q.conditions) > 0 {
+		query += "\n        WHERE " + strings.Join(q.conditions, " AND ")
+	}
+	query += fmt.Sprintf("\n        ORDER BY %s\n        LIMIT %d", order, page.Limit+1)
+
+	return query, q.args
+}
@@ -0,0 +1,222 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// pageSizes are the page sizes the list queries are measured at.
+var pageSizes = []int{1, 10, 100}
+
+// countingConnector counts the statements sent over its connections.
+// Statements executed through prepared statements are counted once, when
+// prepared; the repositories do not prepare statements.
+type countingConnector struct {
+	driver.Connector
+	statements atomic.Int64
+}
+
+func (c *countingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &countingConn{Conn: conn, statements: &c.statements}, nil
+}
+
+type countingConn struct {
+	driver.Conn
+	statements *atomic.Int64
+}
+
+func (c *countingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if !errors.Is(err, driver.ErrSkip) {
+		c.statements.Add(1)
+	}
+	return rows, err
+}
+
+func (c *countingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	result, err := execer.ExecContext(ctx, query, args)
+	if !errors.Is(err, driver.ErrSkip) {
+		c.statements.Add(1)
+	}
+	return result, err
+}
+
+func (c *countingConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	c.statements.Add(1)
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *countingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin() // drivers without BeginTx take no options
+}
+
+// ResetSession and IsValid let the pool discard broken connections, as it
+// does for unwrapped ones.
+func (c *countingConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *countingConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// openCountingDB opens the test database through a connector that counts
+// the statements sent to it.
+func openCountingDB(t testing.TB) (*sql.DB, *countingConnector) {
+	t.Helper()
+	openTestDB(t) // skips without a database and migrates it
+
+	connector, err := pq.NewConnector(os.Getenv(testDSNVariable))
+	if err != nil {
+		t.Fatal(err)
+	}
+	counter := &countingConnector{Connector: connector}
+	db := sql.OpenDB(counter)
+	t.Cleanup(func() { db.Close() })
+	return db, counter
+}
+
+// seedLists creates enough applicants, each with a household, and schemes,
+// each with benefits, to fill the largest page.
+func seedLists(t testing.TB, applicants *ApplicantRepo, schemes *SchemeRepo) {
+	t.Helper()
+	ctx := context.Background()
+	n := pageSizes[len(pageSizes)-1] + 1
+	for i := range n {
+		applicant := &models.Applicant{
+			ID:               uuid.Must(uuid.NewV7()),
+			Name:             fmt.Sprintf("Applicant %d", i),
+			EmploymentStatus: models.EmploymentUnemployed,
+			MaritalStatus:    models.MaritalMarried,
+			Sex:              models.SexFemale,
+			DateOfBirth:      time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC),
+			HouseholdMembers: []models.HouseholdMember{
+				{ID: uuid.Must(uuid.NewV7()), Name: "Spouse", Sex: models.SexMale, Relation: models.RelationSpouse,
+					DateOfBirth: time.Date(1979, time.May, 1, 0, 0, 0, 0, time.UTC)},
+				{ID: uuid.Must(uuid.NewV7()), Name: "Son", Sex: models.SexMale, Relation: models.RelationSon,
+					DateOfBirth: time.Date(2015, time.May, 1, 0, 0, 0, 0, time.UTC)},
+			},
+		}
+		if err := applicants.CreateApplicant(ctx, applicant); err != nil {
+			t.Fatalf("CreateApplicant: %v", err)
+		}
+
+		scheme := &models.Scheme{
+			ID:   uuid.Must(uuid.NewV7()),
+			Name: fmt.Sprintf("Scheme %s", uuid.NewString()),
+			Benefits: []models.Benefit{
+				{ID: uuid.Must(uuid.NewV7()), Name: "Vouchers", Amount: 100},
+				{ID: uuid.Must(uuid.NewV7()), Name: "Cash", Amount: 200},
+			},
+		}
+		if err := schemes.CreateScheme(ctx, scheme); err != nil {
+			t.Fatalf("CreateScheme: %v", err)
+		}
+	}
+}
+
+// listQueries lists a page of each kind of row that is loaded together with
+// its children, returning the number of rows listed.
+var listQueries = []struct {
+	name string
+	list func(ctx context.Context, applicants *ApplicantRepo, schemes *SchemeRepo, limit int) (int, error)
+}{
+	{"applicants", func(ctx context.Context, applicants *ApplicantRepo, _ *SchemeRepo, limit int) (int, error) {
+		page, _, err := applicants.GetAllApplicants(ctx, repository.ApplicantFilter{Page: repository.Page{Limit: limit}})
+		return len(page), err
+	}},
+	{"schemes", func(ctx context.Context, _ *ApplicantRepo, schemes *SchemeRepo, limit int) (int, error) {
+		page, _, err := schemes.GetAllSchemes(ctx, repository.SchemeFilter{Page: repository.Page{Limit: limit}})
+		return len(page), err
+	}},
+}
+
+// TestListStatementCount checks that households and benefits are loaded in
+// one batch per page rather than once per row, so the number of statements
+// a page takes does not grow with its size.
+func TestListStatementCount(t *testing.T) {
+	db, counter := openCountingDB(t)
+	applicants := NewApplicantRepo(db, testKeyring(t, "test-1"))
+	schemes := NewSchemeRepo(db)
+	seedLists(t, applicants, schemes)
+
+	for _, q := range listQueries {
+		t.Run(q.name, func(t *testing.T) {
+			var want int64
+			for _, limit := range pageSizes {
+				counter.statements.Store(0)
+				n, err := q.list(context.Background(), applicants, schemes, limit)
+				if err != nil {
+					t.Fatalf("limit %d: %v", limit, err)
+				}
+				if n != limit {
+					t.Fatalf("limit %d listed %d rows", limit, n)
+				}
+
+				got := counter.statements.Load()
+				if limit == pageSizes[0] {
+					want = got
+				} else if got != want {
+					t.Errorf("limit %d took %d statements, want %d as at limit %d", limit, got, want, pageSizes[0])
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkList(b *testing.B) {
+	db, counter := openCountingDB(b)
+	applicants := NewApplicantRepo(db, testKeyring(b, "test-1"))
+	schemes := NewSchemeRepo(db)
+	seedLists(b, applicants, schemes)
+
+	for _, q := range listQueries {
+		for _, limit := range pageSizes {
+			b.Run(fmt.Sprintf("%s/limit=%d", q.name, limit), func(b *testing.B) {
+				counter.statements.Store(0)
+				for b.Loop() {
+					if _, err := q.list(context.Background(), applicants, schemes, limit); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(counter.statements.Load())/float64(b.N), "statements/op")
+			})
+		}
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type SchemeRepo struct {
@@ -16,158 +18,135 @@ func NewSchemeRepo(db *sql.DB) *SchemeRepo {
 	return &SchemeRepo{db: db}
 }
 
-func (r *SchemeRepo) GetAllSchemes(ctx context.Context) ([]models.Scheme, error) {
-	query := `
-        SELECT s.id, s.name, 
-               c.employment_status, c.marital_status, c.has_children
+const schemeColumns = `
+        SELECT s.id, s.name, s.allow_reapply_after_rejection, s.reapply_cooldown_days, s.version,
+               NULLIF(c.employment_status, ''), NULLIF(c.marital_status, ''), c.has_children,
+               c.min_applicant_age, c.max_applicant_age,
+               c.household_member_min_age, c.household_member_max_age,
+               c.max_household_income, c.max_per_capita_income
         FROM schemes s
-        LEFT JOIN criteria c ON s.id = c.scheme_id
-    `
+        LEFT JOIN criteria c ON s.id = c.scheme_id`
 
-	rows, err := r.db.QueryContext(ctx, query)
+func (r *SchemeRepo) GetAllSchemes(ctx context.Context, filter repository.SchemeFilter) ([]models.Scheme, string, error) {
+	page, err := filter.Page.Resolve(repository.SchemeSortFields)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	defer rows.Close()
 
-	var schemes []models.Scheme
-	for rows.Next() {
-		var scheme models.Scheme
-		var criteria models.Criteria
-		var hasChildren sql.NullBool
+	sortColumn := "s." + page.Field
+	if page.Field == "name" {
+		// Names are compared byte by byte, as the in-memory repository does,
+		// whatever the collation of the database.
+		sortColumn = `s.name COLLATE "C"`
+	}
 
-		err := rows.Scan(
-			&scheme.ID,
-			&scheme.Name,
-			&criteria.EmploymentStatus,
-			&criteria.MaritalStatus,
-			&hasChildren,
-		)
-		if err != nil {
-			return nil, err
-		}
+	var q listQuery
+	query, args := q.build(schemeColumns, sortColumn, "s.id", page)
 
-		criteria.HasChildren = hasChildren.Bool
-		scheme.Criteria = criteria
-		schemes = append(schemes, scheme)
+	schemes, err := r.querySchemes(ctx, query, args...)
+	if err != nil {
+		return nil, "", err
 	}
 
-	for i, scheme := range schemes {
-		benefitsQuery := `
-            SELECT id, name, amount
-            FROM benefits
-            WHERE scheme_id = $1
-        `
-
-		benefitRows, err := r.db.QueryContext(ctx, benefitsQuery, scheme.ID)
-		if err != nil {
-			return nil, err
-		}
-		defer benefitRows.Close()
-
-		var benefits []models.Benefit
-		for benefitRows.Next() {
-			var benefit models.Benefit
-			if err := benefitRows.Scan(&benefit.ID, &benefit.Name, &benefit.Amount); err != nil {
-				return nil, err
-			}
-			benefits = append(benefits, benefit)
-		}
-		schemes[i].Benefits = benefits
+	schemes, next := repository.TrimPage(page, schemes, repository.SchemeSortValue,
+		func(s *models.Scheme) uuid.UUID { return s.ID })
+
+	if err := r.loadBenefits(ctx, schemes); err != nil {
+		return nil, "", err
 	}
 
-	return schemes, nil
+	return schemes, next, nil
 }
 
 func (r *SchemeRepo) GetScheme(ctx context.Context, id uuid.UUID) (*models.Scheme, error) {
+	schemes, err := r.querySchemes(ctx, schemeColumns+"\n        WHERE s.id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(schemes) == 0 {
+		return nil, repository.ErrSchemeNotFound
+	}
 
-	query := `
-        SELECT s.id, s.name, 
-               c.employment_status, c.marital_status,
-               b.id, b.name, b.amount
-        FROM schemes s
-        LEFT JOIN criteria c ON s.id = c.scheme_id
-        LEFT JOIN benefits b ON s.id = b.scheme_id
-        WHERE s.id = $1
-    `
+	if err := r.loadBenefits(ctx, schemes); err != nil {
+		return nil, err
+	}
 
-	rows, err := r.db.QueryContext(ctx, query, id)
+	return &schemes[0], nil
+}
+
+func (r *SchemeRepo) querySchemes(ctx context.Context, query string, args ...any) ([]models.Scheme, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var scheme models.Scheme
-	var hasSchemeName bool
-
+	var schemes []models.Scheme
 	for rows.Next() {
-		var criteria models.Criteria
-		var benefit models.Benefit
-
+		var scheme models.Scheme
 		if err := rows.Scan(
-			&scheme.ID, &scheme.Name,
-			&criteria.EmploymentStatus, &criteria.MaritalStatus,
-			&benefit.ID, &benefit.Name, &benefit.Amount,
+			&scheme.ID,
+			&scheme.Name,
+			&scheme.AllowReapplyAfterRejection,
+			&scheme.ReapplyCooldownDays,
+			&scheme.Version,
+			&scheme.Criteria.EmploymentStatus,
+			&scheme.Criteria.MaritalStatus,
+			&scheme.Criteria.HasChildren,
+			&scheme.Criteria.MinApplicantAge,
+			&scheme.Criteria.MaxApplicantAge,
+			&scheme.Criteria.HouseholdMemberMinAge,
+			&scheme.Criteria.HouseholdMemberMaxAge,
+			&scheme.Criteria.MaxHouseholdIncome,
+			&scheme.Criteria.MaxPerCapitaIncome,
 		); err != nil {
 			return nil, err
 		}
-
-		if !hasSchemeName {
-			scheme.Criteria = criteria
-			hasSchemeName = true
-		}
-		scheme.Benefits = append(scheme.Benefits, benefit)
+		schemes = append(schemes, scheme)
 	}
 
-	if !hasSchemeName {
-		return nil, sql.ErrNoRows
+	return schemes, rows.Err()
+}
+
+// loadBenefits fills in the benefits of all given schemes with a single
+// query.
+func (r *SchemeRepo) loadBenefits(ctx context.Context, schemes []models.Scheme) error {
+	if len(schemes) == 0 {
+		return nil
 	}
 
-	return &scheme, nil
-}
+	ids := make([]string, len(schemes))
+	index := make(map[uuid.UUID]int, len(schemes))
+	for i, scheme := range schemes {
+		ids[i] = scheme.ID.String()
+		index[scheme.ID] = i
+	}
 
-func (r *SchemeRepo) GetEligibleSchemes(ctx context.Context, applicantID uuid.UUID) ([]models.Scheme, error) {
 	query := `
-    SELECT DISTINCT s.id, s.name 
-    FROM schemes s
-    JOIN criteria c ON s.id = c.scheme_id
-    JOIN applicants a ON 
-        (c.employment_status IS NULL OR a.employment_status = c.employment_status)
-        AND (c.marital_status IS NULL OR a.marital_status = c.marital_status)
-        AND (
-            (c.has_children = false AND NOT EXISTS (
-                SELECT 1 FROM household_members hm 
-                WHERE hm.applicant_id = a.id 
-                AND (hm.relation = 'son' OR hm.relation = 'daughter')
-            )) 
-            OR 
-            (c.has_children = true AND EXISTS (
-                SELECT 1 FROM household_members hm 
-                WHERE hm.applicant_id = a.id 
-                AND (hm.relation = 'son' OR hm.relation = 'daughter')
-            ))
-            OR 
-            c.has_children IS NULL
-        )
-    WHERE a.id = $1
-`
-
-	rows, err := r.db.QueryContext(ctx, query, applicantID)
+        SELECT id, name, amount, scheme_id
+        FROM benefits
+        WHERE scheme_id = ANY($1::uuid[])
+        ORDER BY scheme_id, id
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var schemes []models.Scheme
 	for rows.Next() {
-		var scheme models.Scheme
-		if err := rows.Scan(&scheme.ID, &scheme.Name); err != nil {
-			return nil, err
+		var benefit models.Benefit
+		var schemeID uuid.UUID
+		if err := rows.Scan(&benefit.ID, &benefit.Name, &benefit.Amount, &schemeID); err != nil {
+			return err
 		}
-		schemes = append(schemes, scheme)
+
+		scheme := &schemes[index[schemeID]]
+		scheme.Benefits = append(scheme.Benefits, benefit)
 	}
 
-	return schemes, nil
+	return rows.Err()
 }
 
 func (r *SchemeRepo) CreateScheme(ctx context.Context, scheme *models.Scheme) error {
@@ -178,41 +157,230 @@ func (r *SchemeRepo) CreateScheme(ctx context.Context, scheme *models.Scheme) er
 	defer tx.Rollback()
 
 	schemeQuery := `
-        INSERT INTO schemes (id, name)
-        VALUES ($1, $2)
+        INSERT INTO schemes (id, name, allow_reapply_after_rejection, reapply_cooldown_days)
+        VALUES ($1, $2, $3, $4)
     `
-	_, err = tx.ExecContext(ctx, schemeQuery, scheme.ID, scheme.Name)
+	_, err = tx.ExecContext(ctx, schemeQuery,
+		scheme.ID,
+		scheme.Name,
+		scheme.AllowReapplyAfterRejection,
+		scheme.ReapplyCooldownDays,
+	)
+	if isUniqueViolation(err, "schemes_pkey") {
+		return repository.ErrAlreadyExists
+	}
 	if err != nil {
 		return err
 	}
 
-	criteriaQuery := `
-        INSERT INTO criteria (scheme_id, employment_status, marital_status)
-        VALUES ($1, $2, $3)
+	if err := insertCriteria(ctx, tx, scheme.ID, &scheme.Criteria); err != nil {
+		return err
+	}
+
+	for i := range scheme.Benefits {
+		if err := insertBenefit(ctx, tx, scheme.ID, &scheme.Benefits[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	scheme.Version = 1
+	return nil
+}
+
+func (r *SchemeRepo) UpdateScheme(ctx context.Context, scheme *models.Scheme) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	version, err := bumpSchemeVersion(ctx, tx, scheme.ID, scheme.Version)
+	if err != nil {
+		return err
+	}
+
+	query := `
+        UPDATE schemes
+        SET name = $2, allow_reapply_after_rejection = $3, reapply_cooldown_days = $4
+        WHERE id = $1
     `
-	_, err = tx.ExecContext(ctx, criteriaQuery,
+	_, err = tx.ExecContext(ctx, query,
 		scheme.ID,
-		scheme.Criteria.EmploymentStatus,
-		scheme.Criteria.MaritalStatus,
+		scheme.Name,
+		scheme.AllowReapplyAfterRejection,
+		scheme.ReapplyCooldownDays,
 	)
 	if err != nil {
 		return err
 	}
 
-	benefitQuery := `
-        INSERT INTO benefits (id, name, amount)
-        VALUES ($1, $2, $3)
-    `
-	for _, benefit := range scheme.Benefits {
-		_, err = tx.ExecContext(ctx, benefitQuery,
-			benefit.ID,
-			benefit.Name,
-			benefit.Amount,
-		)
-		if err != nil {
+	if err := replaceCriteria(ctx, tx, scheme.ID, &scheme.Criteria); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM benefits WHERE scheme_id = $1`, scheme.ID)
+	if err != nil {
+		return err
+	}
+	for i := range scheme.Benefits {
+		if err := insertBenefit(ctx, tx, scheme.ID, &scheme.Benefits[i]); err != nil {
 			return err
 		}
 	}
 
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	scheme.Version = version
+	return nil
+}
+
+func (r *SchemeRepo) DeleteScheme(ctx context.Context, id uuid.UUID, version int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := bumpSchemeVersion(ctx, tx, id, version); err != nil {
+		return err
+	}
+
+	var referenced bool
+	err = tx.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM applications WHERE scheme_id = $1)`, id,
+	).Scan(&referenced)
+	if err != nil {
+		return err
+	}
+	if referenced {
+		return repository.ErrReferenced
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM benefits WHERE scheme_id = $1`, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM criteria WHERE scheme_id = $1`, id); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM schemes WHERE id = $1`, id)
+	if isForeignKeyViolation(err) {
+		return repository.ErrReferenced
+	}
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(result, repository.ErrSchemeNotFound); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
+
+func (r *SchemeRepo) UpdateCriteria(ctx context.Context, schemeID uuid.UUID, criteria *models.Criteria, version int) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	version, err = bumpSchemeVersion(ctx, tx, schemeID, version)
+	if err != nil {
+		return 0, err
+	}
+	if err := replaceCriteria(ctx, tx, schemeID, criteria); err != nil {
+		return 0, err
+	}
+
+	return version, tx.Commit()
+}
+
+func (r *SchemeRepo) AddBenefit(ctx context.Context, schemeID uuid.UUID, benefit *models.Benefit, version int) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	version, err = bumpSchemeVersion(ctx, tx, schemeID, version)
+	if err != nil {
+		return 0, err
+	}
+	if err := insertBenefit(ctx, tx, schemeID, benefit); err != nil {
+		return 0, err
+	}
+
+	return version, tx.Commit()
+}
+
+func (r *SchemeRepo) DeleteBenefit(ctx context.Context, schemeID, benefitID uuid.UUID, version int) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	version, err = bumpSchemeVersion(ctx, tx, schemeID, version)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx,
+		`DELETE FROM benefits WHERE id = $1 AND scheme_id = $2`,
+		benefitID, schemeID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	if err := expectAffected(result, repository.ErrBenefitNotFound); err != nil {
+		return 0, err
+	}
+
+	return version, tx.Commit()
+}
+
+func replaceCriteria(ctx context.Context, tx *sql.Tx, schemeID uuid.UUID, criteria *models.Criteria) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM criteria WHERE scheme_id = $1`, schemeID); err != nil {
+		return err
+	}
+	return insertCriteria(ctx, tx, schemeID, criteria)
+}
+
+func insertCriteria(ctx context.Context, tx *sql.Tx, schemeID uuid.UUID, criteria *models.Criteria) error {
+	query := `
+        INSERT INTO criteria (scheme_id, employment_status, marital_status, has_children,
+                              min_applicant_age, max_applicant_age,
+                              household_member_min_age, household_member_max_age,
+                              max_household_income, max_per_capita_income)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+    `
+	_, err := tx.ExecContext(ctx, query,
+		schemeID,
+		criteria.EmploymentStatus,
+		criteria.MaritalStatus,
+		criteria.HasChildren,
+		criteria.MinApplicantAge,
+		criteria.MaxApplicantAge,
+		criteria.HouseholdMemberMinAge,
+		criteria.HouseholdMemberMaxAge,
+		criteria.MaxHouseholdIncome,
+		criteria.MaxPerCapitaIncome,
+	)
+	return err
+}
+
+func insertBenefit(ctx context.Context, tx *sql.Tx, schemeID uuid.UUID, benefit *models.Benefit) error {
+	query := `
+        INSERT INTO benefits (id, scheme_id, name, amount)
+        VALUES ($1, $2, $3, $4)
+    `
+	_, err := tx.ExecContext(ctx, query, benefit.ID, schemeID, benefit.Name, benefit.Amount)
+	if isUniqueViolation(err, "benefits_pkey") {
+		return repository.ErrAlreadyExists
+	}
+	return err
+}
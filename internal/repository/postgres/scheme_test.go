@@ -0,0 +1,18 @@
+package postgres
+
+import (
+	"financial_assistance/internal/repository/repotest"
+	"testing"
+)
+
+func TestSchemeRoundTrip(t *testing.T) {
+	repotest.SchemeRoundTrip(t, NewSchemeRepo(openTestDB(t)))
+}
+
+func TestSchemeNameOrder(t *testing.T) {
+	repotest.SchemeNameOrder(t, NewSchemeRepo(openTestDB(t)))
+}
+
+func TestSchemePages(t *testing.T) {
+	repotest.SchemePages(t, NewSchemeRepo(openTestDB(t)))
+}
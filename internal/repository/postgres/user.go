@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type UserRepo struct {
+	db *sql.DB
+}
+
+func NewUserRepo(db *sql.DB) *UserRepo {
+	return &UserRepo{db: db}
+}
+
+func (r *UserRepo) CreateUser(ctx context.Context, user *models.User) error {
+	query := `
+        INSERT INTO users (id, username, password_hash, role, disabled, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+    `
+	_, err := r.db.ExecContext(ctx, query,
+		user.ID,
+		user.Username,
+		user.PasswordHash,
+		user.Role,
+		user.Disabled,
+		user.CreatedAt,
+	)
+	if isUniqueViolation(err, "users_username_key") {
+		return repository.ErrUsernameTaken
+	}
+	if isUniqueViolation(err, "users_pkey") {
+		return repository.ErrAlreadyExists
+	}
+	return err
+}
+
+func (r *UserRepo) GetUser(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	return r.getUser(ctx, `WHERE id = $1`, id)
+}
+
+func (r *UserRepo) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.getUser(ctx, `WHERE username = $1`, username)
+}
+
+func (r *UserRepo) getUser(ctx context.Context, where string, arg any) (*models.User, error) {
+	query := `
+        SELECT id, username, password_hash, role, disabled, created_at
+        FROM users
+        ` + where
+
+	var user models.User
+	err := r.db.QueryRowContext(ctx, query, arg).Scan(
+		&user.ID,
+		&user.Username,
+		&user.PasswordHash,
+		&user.Role,
+		&user.Disabled,
+		&user.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *UserRepo) UpdateUser(ctx context.Context, user *models.User) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE users SET password_hash = $2, role = $3, disabled = $4 WHERE id = $1`,
+		user.ID, user.PasswordHash, user.Role, user.Disabled,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result, repository.ErrUserNotFound)
+}
@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"financial_assistance/internal/repository"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// bumpVersion increments the version of the row of table whose key column
+// is id, provided the row is still at the given version, and returns the new
+// version. The row stays locked until the transaction ends. It returns
+// notFound if there is no such row.
+func bumpVersion(ctx context.Context, tx *sql.Tx, table, key string, id uuid.UUID, version int, notFound error) (int, error) {
+	query := fmt.Sprintf(`UPDATE %s SET version = version + 1 WHERE %s = $1 AND version = $2 RETURNING version`, table, key)
+
+	var next int
+	err := tx.QueryRowContext(ctx, query, id, version).Scan(&next)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return next, err
+	}
+
+	var exists bool
+	query = fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE %s = $1)`, table, key)
+	if err := tx.QueryRowContext(ctx, query, id).Scan(&exists); err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, notFound
+	}
+	return 0, repository.ErrVersionMismatch
+}
+
+func bumpApplicantVersion(ctx context.Context, tx *sql.Tx, id uuid.UUID, version int) (int, error) {
+	return bumpVersion(ctx, tx, "applicants", "id", id, version, repository.ErrApplicantNotFound)
+}
+
+func bumpSchemeVersion(ctx context.Context, tx *sql.Tx, id uuid.UUID, version int) (int, error) {
+	return bumpVersion(ctx, tx, "schemes", "id", id, version, repository.ErrSchemeNotFound)
+}
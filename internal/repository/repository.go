@@ -3,25 +3,94 @@ package repository
 import (
 	"context"
 	"financial_assistance/internal/models"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// Mutating methods take the version of the record the caller last read and
+// return ErrVersionMismatch if it has changed since. On success the version
+// is incremented; UpdateApplicant and UpdateScheme store the new version in
+// their argument, the other methods return it.
+
 type ApplicantRepository interface {
 	CreateApplicant(ctx context.Context, applicant *models.Applicant) error
 	GetApplicant(ctx context.Context, id uuid.UUID) (*models.Applicant, error)
-	GetAllApplicants(ctx context.Context) ([]models.Applicant, error)
+	// GetAllApplicants returns one page of applicants matching the filter and
+	// the cursor of the next page, which is empty on the last page.
+	GetAllApplicants(ctx context.Context, filter ApplicantFilter) ([]models.Applicant, string, error)
+	// UpdateApplicant overwrites the applicant and replaces its household
+	// members with applicant.HouseholdMembers. applicant.Version is the
+	// expected version.
+	UpdateApplicant(ctx context.Context, applicant *models.Applicant) error
+	// DeleteApplicant removes the applicant and its household members. It
+	// returns ErrReferenced if applications still refer to the applicant.
+	DeleteApplicant(ctx context.Context, id uuid.UUID, version int) error
+
+	AddHouseholdMember(ctx context.Context, applicantID uuid.UUID, member *models.HouseholdMember, version int) (int, error)
+	UpdateHouseholdMember(ctx context.Context, applicantID uuid.UUID, member *models.HouseholdMember, version int) (int, error)
+	DeleteHouseholdMember(ctx context.Context, applicantID, memberID uuid.UUID, version int) (int, error)
 }
 
 type SchemeRepository interface {
 	GetScheme(ctx context.Context, id uuid.UUID) (*models.Scheme, error)
-	GetAllSchemes(ctx context.Context) ([]models.Scheme, error)
-	GetEligibleSchemes(ctx context.Context, applicantID uuid.UUID) ([]models.Scheme, error)
+	GetAllSchemes(ctx context.Context, filter SchemeFilter) ([]models.Scheme, string, error)
 	CreateScheme(ctx context.Context, scheme *models.Scheme) error
+	// UpdateScheme overwrites the scheme, its criteria and its benefits.
+	// scheme.Version is the expected version.
+	UpdateScheme(ctx context.Context, scheme *models.Scheme) error
+	// DeleteScheme removes the scheme with its criteria and benefits. It
+	// returns ErrReferenced if applications refer to the scheme.
+	DeleteScheme(ctx context.Context, id uuid.UUID, version int) error
+	UpdateCriteria(ctx context.Context, schemeID uuid.UUID, criteria *models.Criteria, version int) (int, error)
+	AddBenefit(ctx context.Context, schemeID uuid.UUID, benefit *models.Benefit, version int) (int, error)
+	DeleteBenefit(ctx context.Context, schemeID, benefitID uuid.UUID, version int) (int, error)
 }
 
 type ApplicationRepository interface {
-	CreateApplication(ctx context.Context, application *models.Application) error
+	// CreateApplication stores the application together with the event
+	// recording its initial status. It returns ErrActiveApplicationExists if
+	// the applicant already has a non-terminal application for the scheme.
+	CreateApplication(ctx context.Context, application *models.Application, event *models.ApplicationEvent) error
 	GetApplication(ctx context.Context, id uuid.UUID) (*models.Application, error)
-	GetAllApplications(ctx context.Context) ([]models.Application, error)
+	GetAllApplications(ctx context.Context, filter ApplicationFilter) ([]models.Application, string, error)
+	GetApplicationsByApplicantAndScheme(ctx context.Context, applicantID, schemeID uuid.UUID) ([]models.Application, error)
+	// UpdateApplicationStatus moves the application from event.FromStatus to
+	// event.ToStatus and appends the event, returning ErrStatusChanged if the
+	// application is no longer in event.FromStatus.
+	UpdateApplicationStatus(ctx context.Context, event *models.ApplicationEvent, version int) (int, error)
+	GetApplicationEvents(ctx context.Context, applicationID uuid.UUID) ([]models.ApplicationEvent, error)
+}
+
+type UserRepository interface {
+	// CreateUser returns ErrUsernameTaken if the username is in use.
+	CreateUser(ctx context.Context, user *models.User) error
+	GetUser(ctx context.Context, id uuid.UUID) (*models.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	// UpdateUser saves the user's password hash and disabled flag.
+	UpdateUser(ctx context.Context, user *models.User) error
+}
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *models.APIKey) error
+	GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	GetAllAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	// RevokeAPIKey marks the key as revoked at the given time. Revoking a
+	// revoked key keeps the original time.
+	RevokeAPIKey(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+type IdempotencyRepository interface {
+	// ReserveIdempotencyKey stores record as an in-progress request unless an
+	// unexpired record with the same scope and key exists, in which case that
+	// record is returned instead. It returns nil when the key was reserved.
+	ReserveIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord) (*models.IdempotencyRecord, error)
+	// CompleteIdempotencyKey saves the response of a reserved request.
+	CompleteIdempotencyKey(ctx context.Context, record *models.IdempotencyRecord) error
+	// ReleaseIdempotencyKey forgets a reserved request so that it can be
+	// retried.
+	ReleaseIdempotencyKey(ctx context.Context, scope, key string) error
+	// DeleteExpiredIdempotencyKeys removes records that expired before the
+	// given time and returns how many were removed.
+	DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error)
 }
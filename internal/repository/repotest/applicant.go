@@ -0,0 +1,228 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ApplicantLookup checks that applicants are found by exact national ID, and
+// by name together with date of birth, however the name is spaced or
+// capitalized, and that near misses are not.
+func ApplicantLookup(t *testing.T, repo repository.ApplicantRepository) {
+	ctx := context.Background()
+	// Random values keep the lookups apart from applicants of other tests.
+	suffix := strings.ToUpper(newID().String()[24:])
+	name := "Mary Tan " + suffix
+	born := time.Date(1985, time.March, 14, 0, 0, 0, 0, time.UTC)
+
+	create := func(name, nationalID string, dateOfBirth time.Time) models.Applicant {
+		t.Helper()
+		applicant := models.Applicant{
+			ID:               newID(),
+			Name:             name,
+			NationalID:       nationalID,
+			EmploymentStatus: models.EmploymentUnemployed,
+			MaritalStatus:    models.MaritalMarried,
+			Sex:              models.SexFemale,
+			DateOfBirth:      dateOfBirth,
+			HouseholdMembers: []models.HouseholdMember{{
+				ID: newID(), Name: "Gwen Tan", Sex: models.SexFemale, Relation: models.RelationDaughter,
+				DateOfBirth: time.Date(2016, time.February, 1, 0, 0, 0, 0, time.UTC),
+			}},
+		}
+		if err := repo.CreateApplicant(ctx, &applicant); err != nil {
+			t.Fatalf("CreateApplicant: %v", err)
+		}
+		return applicant
+	}
+	mary := create(name, "S"+suffix, born)
+	create(name, "T"+suffix, born.AddDate(0, 0, 1))
+	create("Mary Lim "+suffix, "", born)
+	withoutID := create("Ken Tan "+suffix, "", born)
+
+	tests := []struct {
+		name   string
+		filter repository.ApplicantFilter
+		want   []models.Applicant
+	}{
+		{"national ID", repository.ApplicantFilter{NationalID: "S" + suffix}, []models.Applicant{mary}},
+		{"unknown national ID", repository.ApplicantFilter{NationalID: "U" + suffix}, nil},
+		{"name and date of birth", repository.ApplicantFilter{Name: models.NormalizeName(name), DateOfBirth: born}, []models.Applicant{mary}},
+		{"name as typed", repository.ApplicantFilter{Name: models.NormalizeName("  MARY   tan " + suffix), DateOfBirth: born}, []models.Applicant{mary}},
+		{"without national ID", repository.ApplicantFilter{Name: models.NormalizeName(withoutID.Name), DateOfBirth: born}, []models.Applicant{withoutID}},
+		{"other date of birth", repository.ApplicantFilter{Name: models.NormalizeName(name), DateOfBirth: born.AddDate(-1, 0, 0)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := tt.filter
+			filter.Limit = repository.MaxPageLimit
+			got, _, err := repo.GetAllApplicants(ctx, filter)
+			if err != nil {
+				t.Fatalf("GetAllApplicants: %v", err)
+			}
+			if describeApplicants(got) != describeApplicants(tt.want) {
+				t.Errorf("GetAllApplicants = %s, want %s", describeApplicants(got), describeApplicants(tt.want))
+			}
+			for _, a := range got {
+				if a.Name != mary.Name && a.Name != withoutID.Name || len(a.HouseholdMembers) != 1 {
+					t.Errorf("found %q with %d members, want the stored applicant and household", a.Name, len(a.HouseholdMembers))
+				}
+			}
+		})
+	}
+}
+
+// describeApplicants identifies applicants by ID, name, national ID and
+// date of birth.
+func describeApplicants(applicants []models.Applicant) string {
+	var b strings.Builder
+	for _, a := range applicants {
+		fmt.Fprintf(&b, "[%s %q %q %s]", a.ID, a.Name, a.NationalID, a.DateOfBirth.Format(time.DateOnly))
+	}
+	return b.String()
+}
+
+// HouseholdMembers checks that household members are added, updated and
+// removed with the version of their applicant, and that changes to an
+// unknown applicant or member, at a stale version, or reusing a member ID
+// are refused.
+func HouseholdMembers(t *testing.T, repo repository.ApplicantRepository) {
+	ctx := context.Background()
+	born := time.Date(2016, time.February, 1, 0, 0, 0, 0, time.UTC)
+	newMember := func(name string) models.HouseholdMember {
+		return models.HouseholdMember{
+			ID: newID(), Name: name, Sex: models.SexFemale, Relation: models.RelationDaughter, DateOfBirth: born,
+		}
+	}
+
+	applicant := &models.Applicant{
+		ID:               newID(),
+		Name:             "Household " + newID().String(),
+		EmploymentStatus: models.EmploymentUnemployed,
+		MaritalStatus:    models.MaritalMarried,
+		Sex:              models.SexFemale,
+		DateOfBirth:      time.Date(1985, time.March, 14, 0, 0, 0, 0, time.UTC),
+		HouseholdMembers: []models.HouseholdMember{newMember("Gwen Tan")},
+	}
+	if err := repo.CreateApplicant(ctx, applicant); err != nil {
+		t.Fatalf("CreateApplicant: %v", err)
+	}
+	gwen := applicant.HouseholdMembers[0]
+	expectHousehold(t, repo, applicant.ID, 1, gwen)
+
+	ken := newMember("Ken Tan")
+	ken.Relation = models.RelationSpouse
+	version, err := repo.AddHouseholdMember(ctx, applicant.ID, &ken, 1)
+	if err != nil || version != 2 {
+		t.Fatalf("AddHouseholdMember = %d, %v; want version 2", version, err)
+	}
+	if ken.ApplicantID != applicant.ID {
+		t.Errorf("added member belongs to %s, want %s", ken.ApplicantID, applicant.ID)
+	}
+	expectHousehold(t, repo, applicant.ID, 2, gwen, ken)
+
+	gwen.SchoolLevel = models.SchoolPrimary
+	gwen.MonthlyIncome = 100
+	if version, err = repo.UpdateHouseholdMember(ctx, applicant.ID, &gwen, 2); err != nil || version != 3 {
+		t.Fatalf("UpdateHouseholdMember = %d, %v; want version 3", version, err)
+	}
+	expectHousehold(t, repo, applicant.ID, 3, gwen, ken)
+
+	if version, err = repo.DeleteHouseholdMember(ctx, applicant.ID, ken.ID, 3); err != nil || version != 4 {
+		t.Fatalf("DeleteHouseholdMember = %d, %v; want version 4", version, err)
+	}
+	expectHousehold(t, repo, applicant.ID, 4, gwen)
+
+	// Refused changes leave the applicant at version 4.
+	other := newMember("Ann Tan")
+	refused := []struct {
+		name string
+		err  error
+		call func() (int, error)
+	}{
+		{"add at a stale version", repository.ErrVersionMismatch, func() (int, error) {
+			return repo.AddHouseholdMember(ctx, applicant.ID, &other, 3)
+		}},
+		{"add to an unknown applicant", repository.ErrApplicantNotFound, func() (int, error) {
+			return repo.AddHouseholdMember(ctx, newID(), &other, 1)
+		}},
+		{"add with a member ID in use", repository.ErrAlreadyExists, func() (int, error) {
+			taken := newMember("Ann Tan")
+			taken.ID = gwen.ID
+			return repo.AddHouseholdMember(ctx, applicant.ID, &taken, 4)
+		}},
+		{"update at a stale version", repository.ErrVersionMismatch, func() (int, error) {
+			return repo.UpdateHouseholdMember(ctx, applicant.ID, &gwen, 3)
+		}},
+		{"update an unknown member", repository.ErrHouseholdMemberNotFound, func() (int, error) {
+			return repo.UpdateHouseholdMember(ctx, applicant.ID, &other, 4)
+		}},
+		{"update a removed member", repository.ErrHouseholdMemberNotFound, func() (int, error) {
+			return repo.UpdateHouseholdMember(ctx, applicant.ID, &ken, 4)
+		}},
+		{"delete at a stale version", repository.ErrVersionMismatch, func() (int, error) {
+			return repo.DeleteHouseholdMember(ctx, applicant.ID, gwen.ID, 5)
+		}},
+		{"delete an unknown member", repository.ErrHouseholdMemberNotFound, func() (int, error) {
+			return repo.DeleteHouseholdMember(ctx, applicant.ID, newID(), 4)
+		}},
+		{"delete from an unknown applicant", repository.ErrApplicantNotFound, func() (int, error) {
+			return repo.DeleteHouseholdMember(ctx, newID(), gwen.ID, 1)
+		}},
+	}
+	for _, tt := range refused {
+		if version, err := tt.call(); !errors.Is(err, tt.err) {
+			t.Errorf("%s = %d, %v; want %v", tt.name, version, err, tt.err)
+		}
+	}
+	expectHousehold(t, repo, applicant.ID, 4, gwen)
+
+	// Updating the applicant replaces the whole household.
+	stored, err := repo.GetApplicant(ctx, applicant.ID)
+	if err != nil {
+		t.Fatalf("GetApplicant: %v", err)
+	}
+	stored.HouseholdMembers = []models.HouseholdMember{other}
+	if err := repo.UpdateApplicant(ctx, stored); err != nil {
+		t.Fatalf("UpdateApplicant: %v", err)
+	}
+	other.ApplicantID = applicant.ID
+	expectHousehold(t, repo, applicant.ID, 5, other)
+}
+
+// expectHousehold checks the version and household of a stored applicant.
+// Members may be listed in any order.
+func expectHousehold(t *testing.T, repo repository.ApplicantRepository, applicantID uuid.UUID, version int, want ...models.HouseholdMember) {
+	t.Helper()
+	applicant, err := repo.GetApplicant(context.Background(), applicantID)
+	if err != nil {
+		t.Fatalf("GetApplicant: %v", err)
+	}
+	if applicant.Version != version {
+		t.Errorf("applicant is at version %d, want %d", applicant.Version, version)
+	}
+	if got, w := describeMembers(applicant.HouseholdMembers), describeMembers(want); got != w {
+		t.Errorf("household = %s, want %s", got, w)
+	}
+}
+
+// describeMembers identifies household members by every stored field,
+// ordered by ID.
+func describeMembers(members []models.HouseholdMember) string {
+	described := make([]string, len(members))
+	for i, m := range members {
+		described[i] = fmt.Sprintf("[%s %q %s %s %s %s %q %v of %s]", m.ID, m.Name, m.EmploymentStatus, m.Sex,
+			m.DateOfBirth.Format(time.DateOnly), m.Relation, m.SchoolLevel, m.MonthlyIncome, m.ApplicantID)
+	}
+	slices.Sort(described)
+	return strings.Join(described, "")
+}
@@ -0,0 +1,218 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// createApplicantAndScheme stores an applicant and a scheme for
+// applications to refer to.
+func createApplicantAndScheme(t *testing.T, applicants repository.ApplicantRepository, schemes repository.SchemeRepository) (applicantID, schemeID uuid.UUID) {
+	t.Helper()
+	ctx := context.Background()
+	applicant := &models.Applicant{
+		ID:               newID(),
+		Name:             "Applicant " + newID().String(),
+		EmploymentStatus: models.EmploymentUnemployed,
+		MaritalStatus:    models.MaritalSingle,
+		Sex:              models.SexFemale,
+		DateOfBirth:      time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if err := applicants.CreateApplicant(ctx, applicant); err != nil {
+		t.Fatalf("CreateApplicant: %v", err)
+	}
+	scheme := &models.Scheme{ID: newID(), Name: "Scheme " + newID().String()}
+	if err := schemes.CreateScheme(ctx, scheme); err != nil {
+		t.Fatalf("CreateScheme: %v", err)
+	}
+	return applicant.ID, scheme.ID
+}
+
+// ApplicationHistory checks that an application's events are stored with
+// every field and listed in order, and that refused status changes add none.
+func ApplicationHistory(t *testing.T, applicants repository.ApplicantRepository, schemes repository.SchemeRepository, applications repository.ApplicationRepository) {
+	ctx := context.Background()
+	applicantID, schemeID := createApplicantAndScheme(t, applicants, schemes)
+
+	// Times are whole seconds, which every store keeps exactly.
+	at := time.Now().UTC().Truncate(time.Second)
+	application := &models.Application{
+		ID:          newID(),
+		ApplicantID: applicantID,
+		SchemeID:    schemeID,
+		Status:      models.StatusDraft,
+		CreatedAt:   at,
+		UpdatedAt:   at,
+	}
+	want := []models.ApplicationEvent{{
+		ID: newID(), ApplicationID: application.ID, ToStatus: models.StatusDraft, Actor: "user:alice", CreatedAt: at,
+	}}
+	if err := applications.CreateApplication(ctx, application, &want[0]); err != nil {
+		t.Fatalf("CreateApplication: %v", err)
+	}
+
+	version := application.Version
+	for i, move := range []struct {
+		to     models.ApplicationStatus
+		reason string
+	}{
+		{models.StatusSubmitted, ""},
+		{models.StatusUnderReview, "documents_received"},
+	} {
+		event := models.ApplicationEvent{
+			ID:            newID(),
+			ApplicationID: application.ID,
+			FromStatus:    want[len(want)-1].ToStatus,
+			ToStatus:      move.to,
+			Actor:         "api_key:" + uuid.NewString(),
+			ReasonCode:    move.reason,
+			CreatedAt:     at.Add(time.Duration(i+1) * time.Minute),
+		}
+		next, err := applications.UpdateApplicationStatus(ctx, &event, version)
+		if err != nil {
+			t.Fatalf("UpdateApplicationStatus to %s: %v", move.to, err)
+		}
+		if next != version+1 {
+			t.Errorf("UpdateApplicationStatus returned version %d, want %d", next, version+1)
+		}
+		version = next
+		want = append(want, event)
+	}
+
+	// Refused changes: a stale version, and a move from a status the
+	// application has left.
+	stale := models.ApplicationEvent{
+		ID: newID(), ApplicationID: application.ID, FromStatus: models.StatusUnderReview,
+		ToStatus: models.StatusApproved, Actor: "user:bob", CreatedAt: at.Add(time.Hour),
+	}
+	if _, err := applications.UpdateApplicationStatus(ctx, &stale, version-1); !errors.Is(err, repository.ErrVersionMismatch) {
+		t.Errorf("UpdateApplicationStatus with a stale version = %v, want ErrVersionMismatch", err)
+	}
+	moved := stale
+	moved.ID, moved.FromStatus = newID(), models.StatusSubmitted
+	if _, err := applications.UpdateApplicationStatus(ctx, &moved, version); !errors.Is(err, repository.ErrStatusChanged) {
+		t.Errorf("UpdateApplicationStatus from a former status = %v, want ErrStatusChanged", err)
+	}
+
+	events, err := applications.GetApplicationEvents(ctx, application.ID)
+	if err != nil {
+		t.Fatalf("GetApplicationEvents: %v", err)
+	}
+	if len(events) != len(want) {
+		t.Fatalf("GetApplicationEvents returned %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i := range want {
+		got, w := events[i], want[i]
+		if got.ID != w.ID || got.ApplicationID != w.ApplicationID || got.FromStatus != w.FromStatus ||
+			got.ToStatus != w.ToStatus || got.Actor != w.Actor || got.ReasonCode != w.ReasonCode || !got.CreatedAt.Equal(w.CreatedAt) {
+			t.Errorf("event %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	stored, err := applications.GetApplication(ctx, application.ID)
+	if err != nil {
+		t.Fatalf("GetApplication: %v", err)
+	}
+	last := want[len(want)-1]
+	if stored.Status != last.ToStatus || !stored.UpdatedAt.Equal(last.CreatedAt) || stored.Version != version {
+		t.Errorf("application is %s, updated %v at version %d; want %s, updated %v at version %d",
+			stored.Status, stored.UpdatedAt, stored.Version, last.ToStatus, last.CreatedAt, version)
+	}
+
+	if events, err := applications.GetApplicationEvents(ctx, uuid.New()); err != nil || len(events) != 0 {
+		t.Errorf("GetApplicationEvents of an unknown application = %v, %v; want none", events, err)
+	}
+}
+
+// ApplicationCRUD checks that applications are stored and found by ID and by
+// applicant and scheme, that IDs cannot be reused, and that applicants and
+// schemes with applications cannot be deleted.
+func ApplicationCRUD(t *testing.T, applicants repository.ApplicantRepository, schemes repository.SchemeRepository, applications repository.ApplicationRepository) {
+	ctx := context.Background()
+	applicantID, schemeID := createApplicantAndScheme(t, applicants, schemes)
+	_, otherSchemeID := createApplicantAndScheme(t, applicants, schemes)
+
+	at := time.Now().UTC().Truncate(time.Second)
+	create := func(schemeID uuid.UUID, status models.ApplicationStatus, id uuid.UUID) (*models.Application, error) {
+		application := &models.Application{
+			ID: id, ApplicantID: applicantID, SchemeID: schemeID, Status: status, CreatedAt: at, UpdatedAt: at,
+		}
+		event := &models.ApplicationEvent{
+			ID: newID(), ApplicationID: id, ToStatus: status, Actor: "test", CreatedAt: at,
+		}
+		return application, applications.CreateApplication(ctx, application, event)
+	}
+
+	rejected, err := create(schemeID, models.StatusRejected, newID())
+	if err != nil {
+		t.Fatalf("CreateApplication: %v", err)
+	}
+	draft, err := create(schemeID, models.StatusDraft, newID())
+	if err != nil {
+		t.Fatalf("CreateApplication: %v", err)
+	}
+	if draft.Version != 1 {
+		t.Errorf("new application is at version %d, want 1", draft.Version)
+	}
+
+	got, err := applications.GetApplication(ctx, draft.ID)
+	if err != nil {
+		t.Fatalf("GetApplication: %v", err)
+	}
+	if got.ID != draft.ID || got.ApplicantID != applicantID || got.SchemeID != schemeID || got.Status != models.StatusDraft ||
+		!got.CreatedAt.Equal(at) || !got.UpdatedAt.Equal(at) || got.Version != 1 {
+		t.Errorf("GetApplication = %+v, want %+v", got, draft)
+	}
+	if _, err := applications.GetApplication(ctx, newID()); !errors.Is(err, repository.ErrApplicationNotFound) {
+		t.Errorf("GetApplication of an unknown ID = %v, want ErrApplicationNotFound", err)
+	}
+
+	if _, err := create(otherSchemeID, models.StatusDraft, draft.ID); !errors.Is(err, repository.ErrAlreadyExists) {
+		t.Errorf("CreateApplication with an ID in use = %v, want ErrAlreadyExists", err)
+	}
+	if _, err := create(schemeID, models.StatusSubmitted, newID()); !errors.Is(err, repository.ErrActiveApplicationExists) {
+		t.Errorf("CreateApplication beside an active one = %v, want ErrActiveApplicationExists", err)
+	}
+
+	found, err := applications.GetApplicationsByApplicantAndScheme(ctx, applicantID, schemeID)
+	if err != nil {
+		t.Fatalf("GetApplicationsByApplicantAndScheme: %v", err)
+	}
+	ids := make([]string, len(found))
+	for i := range found {
+		ids[i] = found[i].ID.String()
+	}
+	slices.Sort(ids)
+	want := []string{rejected.ID.String(), draft.ID.String()}
+	slices.Sort(want)
+	if !slices.Equal(ids, want) {
+		t.Errorf("GetApplicationsByApplicantAndScheme = %v, want %v", ids, want)
+	}
+	if found, err := applications.GetApplicationsByApplicantAndScheme(ctx, applicantID, otherSchemeID); err != nil || len(found) != 0 {
+		t.Errorf("GetApplicationsByApplicantAndScheme for a scheme without applications = %v, %v; want none", found, err)
+	}
+
+	event := &models.ApplicationEvent{
+		ID: newID(), ApplicationID: newID(), FromStatus: models.StatusDraft, ToStatus: models.StatusSubmitted, Actor: "test", CreatedAt: at,
+	}
+	if _, err := applications.UpdateApplicationStatus(ctx, event, 1); !errors.Is(err, repository.ErrApplicationNotFound) {
+		t.Errorf("UpdateApplicationStatus of an unknown application = %v, want ErrApplicationNotFound", err)
+	}
+
+	if err := applicants.DeleteApplicant(ctx, applicantID, 1); !errors.Is(err, repository.ErrReferenced) {
+		t.Errorf("DeleteApplicant with applications = %v, want ErrReferenced", err)
+	}
+	if err := schemes.DeleteScheme(ctx, schemeID, 1); !errors.Is(err, repository.ErrReferenced) {
+		t.Errorf("DeleteScheme with applications = %v, want ErrReferenced", err)
+	}
+	if err := schemes.DeleteScheme(ctx, otherSchemeID, 1); err != nil {
+		t.Errorf("DeleteScheme without applications: %v", err)
+	}
+}
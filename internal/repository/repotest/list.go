@@ -0,0 +1,252 @@
+package repotest
+
+import (
+	"cmp"
+	"context"
+	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// listFunc lists one page of a listing.
+type listFunc[T any] func(page repository.Page) ([]T, string, error)
+
+// checkPages pages through a listing with the page size and sort given,
+// following next cursors until every item of want has been listed, and
+// checks that each was listed exactly once, in the order of sort. The
+// repository may hold other items, which are ignored. It returns the items
+// listed.
+func checkPages[T any](t *testing.T, list listFunc[T], limit int, sort string, want []uuid.UUID, sortValue func(*T, string) string, id func(*T) uuid.UUID) []T {
+	t.Helper()
+	seen := make(map[uuid.UUID]int, len(want))
+	for _, itemID := range want {
+		seen[itemID] = 0
+	}
+
+	var listed, ours []T
+	page := repository.Page{Limit: limit, Sort: sort}
+	for found := 0; found < len(want); {
+		items, next, err := list(page)
+		if err != nil {
+			t.Fatalf("sort=%s: listing page after %q: %v", sort, page.Cursor, err)
+		}
+		if len(items) > limit || next != "" && len(items) < limit {
+			t.Fatalf("sort=%s: page of %d items with next cursor %q, want %d items before the last page", sort, len(items), next, limit)
+		}
+		for i := range items {
+			if n, ok := seen[id(&items[i])]; ok {
+				seen[id(&items[i])] = n + 1
+				found++
+				ours = append(ours, items[i])
+			}
+		}
+		listed = append(listed, items...)
+		if next == "" {
+			break
+		}
+		page.Cursor = next
+	}
+
+	for itemID, n := range seen {
+		if n != 1 {
+			t.Errorf("sort=%s listed %s %d times, want once", sort, itemID, n)
+		}
+	}
+
+	field, desc := strings.TrimPrefix(sort, "-"), strings.HasPrefix(sort, "-")
+	for i := 1; i < len(ours); i++ {
+		prev, cur := &ours[i-1], &ours[i]
+		c := cmp.Or(
+			strings.Compare(sortValue(prev, field), sortValue(cur, field)),
+			strings.Compare(id(prev).String(), id(cur).String()),
+		)
+		if desc {
+			c = -c
+		}
+		if c >= 0 {
+			t.Errorf("sort=%s listed %s (%s) before %s (%s)", sort,
+				id(prev), sortValue(prev, field), id(cur), sortValue(cur, field))
+		}
+	}
+	return listed
+}
+
+// SchemePages checks that paging through schemes with every sort and page
+// size lists each scheme exactly once, including schemes whose names tie.
+func SchemePages(t *testing.T, repo repository.SchemeRepository) {
+	ctx := context.Background()
+	prefix := "pages-" + uuid.NewString() + " "
+	var ids []uuid.UUID
+	for i := range 10 {
+		scheme := &models.Scheme{ID: newID(), Name: prefix + []string{"alpha", "beta", "gamma"}[i%3]}
+		if err := repo.CreateScheme(ctx, scheme); err != nil {
+			t.Fatalf("CreateScheme: %v", err)
+		}
+		ids = append(ids, scheme.ID)
+	}
+
+	list := func(page repository.Page) ([]models.Scheme, string, error) {
+		return repo.GetAllSchemes(ctx, repository.SchemeFilter{Page: page})
+	}
+	for _, sort := range []string{"", "id", "-id", "name", "-name"} {
+		for _, limit := range []int{1, 3, 10} {
+			t.Run(fmt.Sprintf("sort=%s limit=%d", sort, limit), func(t *testing.T) {
+				checkPages(t, list, limit, sort, ids, repository.SchemeSortValue,
+					func(s *models.Scheme) uuid.UUID { return s.ID })
+			})
+		}
+	}
+}
+
+// ApplicantFilters checks that applicants are filtered by employment and
+// marital status and paged through by ID.
+func ApplicantFilters(t *testing.T, repo repository.ApplicantRepository) {
+	ctx := context.Background()
+	type kind struct {
+		employment models.EmploymentStatus
+		marital    models.MaritalStatus
+	}
+	kinds := []kind{
+		{models.EmploymentUnemployed, models.MaritalSingle},
+		{models.EmploymentUnemployed, models.MaritalMarried},
+		{models.EmploymentEmployed, models.MaritalMarried},
+	}
+	created := make(map[kind][]uuid.UUID)
+	for i := range 9 {
+		k := kinds[i%len(kinds)]
+		applicant := &models.Applicant{
+			ID:               newID(),
+			Name:             "Filter " + newID().String(),
+			EmploymentStatus: k.employment,
+			MaritalStatus:    k.marital,
+			Sex:              models.SexMale,
+			DateOfBirth:      time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC),
+		}
+		if err := repo.CreateApplicant(ctx, applicant); err != nil {
+			t.Fatalf("CreateApplicant: %v", err)
+		}
+		created[k] = append(created[k], applicant.ID)
+	}
+
+	tests := []struct {
+		name   string
+		filter repository.ApplicantFilter
+		match  func(kind) bool
+	}{
+		{"unemployed", repository.ApplicantFilter{EmploymentStatus: models.EmploymentUnemployed},
+			func(k kind) bool { return k.employment == models.EmploymentUnemployed }},
+		{"married", repository.ApplicantFilter{MaritalStatus: models.MaritalMarried},
+			func(k kind) bool { return k.marital == models.MaritalMarried }},
+		{"unemployed and married",
+			repository.ApplicantFilter{EmploymentStatus: models.EmploymentUnemployed, MaritalStatus: models.MaritalMarried},
+			func(k kind) bool { return k == kinds[1] }},
+	}
+	for _, tt := range tests {
+		for _, sort := range []string{"id", "-id"} {
+			t.Run(tt.name+" sort="+sort, func(t *testing.T) {
+				list := func(page repository.Page) ([]models.Applicant, string, error) {
+					filter := tt.filter
+					filter.Page = page
+					return repo.GetAllApplicants(ctx, filter)
+				}
+				var want []uuid.UUID
+				for k, ids := range created {
+					if tt.match(k) {
+						want = append(want, ids...)
+					}
+				}
+				listed := checkPages(t, list, 2, sort, want, repository.ApplicantSortValue,
+					func(a *models.Applicant) uuid.UUID { return a.ID })
+				for _, a := range listed {
+					if !tt.match(kind{a.EmploymentStatus, a.MaritalStatus}) {
+						t.Errorf("listed %s, which is %s and %s", a.ID, a.EmploymentStatus, a.MaritalStatus)
+					}
+				}
+			})
+		}
+	}
+}
+
+// ApplicationPages checks that applications are filtered by status,
+// applicant and scheme, and that paging through them with every sort lists
+// each exactly once although their timestamps tie.
+func ApplicationPages(t *testing.T, applicants repository.ApplicantRepository, schemes repository.SchemeRepository, applications repository.ApplicationRepository) {
+	ctx := context.Background()
+	var applicantIDs, schemeIDs []uuid.UUID
+	for range 3 {
+		applicantID, schemeID := createApplicantAndScheme(t, applicants, schemes)
+		applicantIDs = append(applicantIDs, applicantID)
+		schemeIDs = append(schemeIDs, schemeID)
+	}
+
+	// Every application is created at the same instant, so that only the ID
+	// tells them apart when sorting by time.
+	at := time.Now().UTC().Truncate(time.Second)
+	statuses := []models.ApplicationStatus{models.StatusDraft, models.StatusSubmitted, models.StatusRejected}
+	var created []models.Application
+	for i, applicantID := range applicantIDs {
+		for j, schemeID := range schemeIDs {
+			application := &models.Application{
+				ID:          newID(),
+				ApplicantID: applicantID,
+				SchemeID:    schemeID,
+				Status:      statuses[(i+j)%len(statuses)],
+				CreatedAt:   at,
+				UpdatedAt:   at,
+			}
+			event := &models.ApplicationEvent{
+				ID: newID(), ApplicationID: application.ID, ToStatus: application.Status, Actor: "test", CreatedAt: at,
+			}
+			if err := applications.CreateApplication(ctx, application, event); err != nil {
+				t.Fatalf("CreateApplication: %v", err)
+			}
+			created = append(created, *application)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter repository.ApplicationFilter
+		match  func(*models.Application) bool
+	}{
+		{"all", repository.ApplicationFilter{},
+			func(a *models.Application) bool { return true }},
+		{"status", repository.ApplicationFilter{Status: models.StatusSubmitted},
+			func(a *models.Application) bool { return a.Status == models.StatusSubmitted }},
+		{"applicant", repository.ApplicationFilter{ApplicantID: applicantIDs[1]},
+			func(a *models.Application) bool { return a.ApplicantID == applicantIDs[1] }},
+		{"scheme", repository.ApplicationFilter{SchemeID: schemeIDs[2]},
+			func(a *models.Application) bool { return a.SchemeID == schemeIDs[2] }},
+		{"scheme and status", repository.ApplicationFilter{SchemeID: schemeIDs[0], Status: models.StatusDraft},
+			func(a *models.Application) bool { return a.SchemeID == schemeIDs[0] && a.Status == models.StatusDraft }},
+	}
+	for _, tt := range tests {
+		for _, sort := range []string{"created_at", "-created_at", "updated_at", "-updated_at", "id", "-id"} {
+			t.Run(tt.name+" sort="+sort, func(t *testing.T) {
+				list := func(page repository.Page) ([]models.Application, string, error) {
+					filter := tt.filter
+					filter.Page = page
+					return applications.GetAllApplications(ctx, filter)
+				}
+				var want []uuid.UUID
+				for i := range created {
+					if tt.match(&created[i]) {
+						want = append(want, created[i].ID)
+					}
+				}
+				listed := checkPages(t, list, 2, sort, want, repository.ApplicationSortValue,
+					func(a *models.Application) uuid.UUID { return a.ID })
+				for i := range listed {
+					if !tt.match(&listed[i]) {
+						t.Errorf("listed %s, which does not match the filter", listed[i].ID)
+					}
+				}
+			})
+		}
+	}
+}
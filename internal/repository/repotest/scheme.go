@@ -0,0 +1,187 @@
+// Package repotest holds the tests that every implementation of the
+// repository interfaces must pass, so that the in-memory and PostgreSQL
+// repositories behave alike.
+package repotest
+
+import (
+	"context"
+	"encoding/json"
+	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// newID returns a time-ordered ID like the service assigns, so that
+// benefits are listed in the order they were created.
+func newID() uuid.UUID {
+	return uuid.Must(uuid.NewV7())
+}
+
+// SchemeRoundTrip checks that a scheme is read back exactly as it was
+// created, by GetScheme and by GetAllSchemes.
+func SchemeRoundTrip(t *testing.T, repo repository.SchemeRepository) {
+	tests := []struct {
+		name   string
+		scheme models.Scheme
+	}{
+		{
+			name: "every criterion and benefits",
+			scheme: models.Scheme{
+				Name: "Retrenchment Assistance",
+				Criteria: models.Criteria{
+					EmploymentStatus:      ptr(models.EmploymentUnemployed),
+					MaritalStatus:         ptr(models.MaritalMarried),
+					HasChildren:           ptr(true),
+					MinApplicantAge:       ptr(21),
+					MaxApplicantAge:       ptr(65),
+					HouseholdMemberMinAge: ptr(0),
+					HouseholdMemberMaxAge: ptr(12),
+					MaxHouseholdIncome:    ptr(3000.0),
+					MaxPerCapitaIncome:    ptr(750.5),
+				},
+				Benefits: []models.Benefit{
+					{ID: newID(), Name: "CDC Vouchers", Amount: 500},
+					{ID: newID(), Name: "School Meal Vouchers", Amount: 120.25},
+				},
+				AllowReapplyAfterRejection: true,
+				ReapplyCooldownDays:        30,
+			},
+		},
+		{
+			name:   "no criteria and no benefits",
+			scheme: models.Scheme{Name: "Universal Grant"},
+		},
+		{
+			// An explicit false must not be read back as "any".
+			name: "without children",
+			scheme: models.Scheme{
+				Name:     "Seniors Without Dependants",
+				Criteria: models.Criteria{HasChildren: ptr(false), MinApplicantAge: ptr(0)},
+			},
+		},
+		{
+			name: "some criteria",
+			scheme: models.Scheme{
+				Name:     "Single Parents",
+				Criteria: models.Criteria{MaritalStatus: ptr(models.MaritalSingle), HasChildren: ptr(true)},
+				Benefits: []models.Benefit{{ID: newID(), Name: "Childcare Subsidy", Amount: 0}},
+			},
+		},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.scheme
+			want.ID = newID()
+			created := want
+			created.Benefits = append([]models.Benefit(nil), want.Benefits...)
+			if err := repo.CreateScheme(ctx, &created); err != nil {
+				t.Fatalf("CreateScheme: %v", err)
+			}
+			want.Version = 1
+			if created.Version != want.Version {
+				t.Errorf("CreateScheme set version %d, want %d", created.Version, want.Version)
+			}
+
+			got, err := repo.GetScheme(ctx, want.ID)
+			if err != nil {
+				t.Fatalf("GetScheme: %v", err)
+			}
+			if !reflect.DeepEqual(*got, want) {
+				t.Errorf("GetScheme =\n%s\nwant\n%s", describeScheme(got), describeScheme(&want))
+			}
+
+			listed := findScheme(t, repo, want.ID)
+			if !reflect.DeepEqual(*listed, want) {
+				t.Errorf("GetAllSchemes =\n%s\nwant\n%s", describeScheme(listed), describeScheme(&want))
+			}
+		})
+	}
+}
+
+// findScheme pages through GetAllSchemes until it finds the scheme, since
+// the repository may hold schemes of other tests.
+func findScheme(t *testing.T, repo repository.SchemeRepository, id uuid.UUID) *models.Scheme {
+	t.Helper()
+	filter := repository.SchemeFilter{Page: repository.Page{Limit: repository.MaxPageLimit}}
+	for {
+		schemes, next, err := repo.GetAllSchemes(context.Background(), filter)
+		if err != nil {
+			t.Fatalf("GetAllSchemes: %v", err)
+		}
+		for i := range schemes {
+			if schemes[i].ID == id {
+				return &schemes[i]
+			}
+		}
+		if next == "" {
+			t.Fatalf("GetAllSchemes does not list scheme %s", id)
+		}
+		filter.Cursor = next
+	}
+}
+
+// describeScheme renders a scheme with the values its pointers point to.
+func describeScheme(s *models.Scheme) string {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
+
+// SchemeNameOrder checks that schemes sorted by name are paged through in
+// byte order, upper case before lower case, in both directions.
+func SchemeNameOrder(t *testing.T, repo repository.SchemeRepository) {
+	ctx := context.Background()
+
+	// The names share a random prefix so that other schemes in the
+	// repository sort before or after all of them.
+	prefix := "order-" + uuid.NewString() + " "
+	names := []string{"banana", "Banana", "apple", "Cherry", "Apple", "apple pie", "Zucchini"}
+	ids := make(map[uuid.UUID]string, len(names))
+	for _, name := range names {
+		scheme := &models.Scheme{ID: newID(), Name: prefix + name}
+		if err := repo.CreateScheme(ctx, scheme); err != nil {
+			t.Fatalf("CreateScheme: %v", err)
+		}
+		ids[scheme.ID] = name
+	}
+
+	want := []string{"Apple", "Banana", "Cherry", "Zucchini", "apple", "apple pie", "banana"}
+	for _, sort := range []string{"name", "-name"} {
+		var got []string
+		filter := repository.SchemeFilter{Page: repository.Page{Limit: 3, Sort: sort}}
+		for {
+			schemes, next, err := repo.GetAllSchemes(ctx, filter)
+			if err != nil {
+				t.Fatalf("GetAllSchemes(sort=%s): %v", sort, err)
+			}
+			for _, scheme := range schemes {
+				if name, ok := ids[scheme.ID]; ok {
+					got = append(got, name)
+				}
+			}
+			if next == "" {
+				break
+			}
+			filter.Cursor = next
+		}
+
+		if sort == "-name" {
+			slices.Reverse(got)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("sort=%s listed %q, want %q", sort, got, want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestPair creates an applicant and a scheme, without criteria, with the
+// reapplication policy given.
+func newTestPair(t *testing.T, svc *Service, allowAfterRejection bool, cooldownDays int) (*models.Applicant, *models.Scheme) {
+	t.Helper()
+	ctx := as(models.RoleAdmin)
+	applicant := &models.Applicant{
+		Name:             "Mary Tan",
+		EmploymentStatus: models.EmploymentUnemployed,
+		MaritalStatus:    models.MaritalMarried,
+		Sex:              models.SexFemale,
+		DateOfBirth:      time.Date(1985, time.March, 14, 0, 0, 0, 0, time.UTC),
+	}
+	if err := svc.CreateApplicant(ctx, applicant); err != nil {
+		t.Fatalf("CreateApplicant: %v", err)
+	}
+	scheme := &models.Scheme{
+		Name:                       "Retrenchment Assistance",
+		AllowReapplyAfterRejection: allowAfterRejection,
+		ReapplyCooldownDays:        cooldownDays,
+	}
+	if err := svc.CreateScheme(ctx, scheme); err != nil {
+		t.Fatalf("CreateScheme: %v", err)
+	}
+	return applicant, scheme
+}
+
+// closedApplication stores an application of applicant for scheme that
+// reached status closedAgo ago, bypassing the service.
+func closedApplication(t *testing.T, svc *Service, applicant *models.Applicant, scheme *models.Scheme, status models.ApplicationStatus, closedAgo time.Duration) {
+	t.Helper()
+	closed := time.Now().UTC().Add(-closedAgo)
+	application := &models.Application{
+		ID:          newID(),
+		ApplicantID: applicant.ID,
+		SchemeID:    scheme.ID,
+		Status:      status,
+		CreatedAt:   closed.AddDate(0, 0, -7),
+		UpdatedAt:   closed,
+	}
+	event := newApplicationEvent(application.ID, "", status, "test", "", closed)
+	if err := svc.applicationRepo.CreateApplication(context.Background(), application, event); err != nil {
+		t.Fatalf("CreateApplication: %v", err)
+	}
+}
+
+func apply(svc *Service, applicant *models.Applicant, scheme *models.Scheme) error {
+	return svc.CreateApplication(as(models.RoleCaseworker), &models.Application{ApplicantID: applicant.ID, SchemeID: scheme.ID})
+}
+
+func TestSecondActiveApplicationIsRefused(t *testing.T) {
+	for _, status := range []models.ApplicationStatus{
+		models.StatusDraft, models.StatusSubmitted, models.StatusUnderReview, models.StatusApproved,
+	} {
+		t.Run(string(status), func(t *testing.T) {
+			svc := newTestService()
+			applicant, scheme := newTestPair(t, svc, true, 0)
+			closedApplication(t, svc, applicant, scheme, status, time.Hour)
+
+			if err := apply(svc, applicant, scheme); !errors.Is(err, ErrDuplicateApplication) {
+				t.Errorf("second application while one is %s = %v, want ErrDuplicateApplication", status, err)
+			}
+		})
+	}
+
+	// Applications of the same applicant for other schemes are independent.
+	svc := newTestService()
+	applicant, scheme := newTestPair(t, svc, true, 0)
+	if err := apply(svc, applicant, scheme); err != nil {
+		t.Fatalf("first application: %v", err)
+	}
+	_, other := newTestPair(t, svc, true, 0)
+	if err := apply(svc, applicant, other); err != nil {
+		t.Errorf("application for another scheme = %v, want it accepted", err)
+	}
+}
+
+// TestActiveApplicationIndex checks the guard that the repositories keep
+// against concurrent creates, which the service check cannot see.
+func TestActiveApplicationIndex(t *testing.T) {
+	svc := newTestService()
+	applicant, scheme := newTestPair(t, svc, true, 0)
+	closedApplication(t, svc, applicant, scheme, models.StatusSubmitted, time.Hour)
+
+	application := &models.Application{ID: newID(), ApplicantID: applicant.ID, SchemeID: scheme.ID, Status: models.StatusDraft}
+	event := newApplicationEvent(application.ID, "", application.Status, "test", "", time.Now())
+	err := svc.applicationRepo.CreateApplication(context.Background(), application, event)
+	if !errors.Is(err, repository.ErrActiveApplicationExists) {
+		t.Errorf("storing a second active application = %v, want ErrActiveApplicationExists", err)
+	}
+}
+
+func TestReapplication(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		name                string
+		allowAfterRejection bool
+		cooldownDays        int
+		previous            models.ApplicationStatus
+		closedAgo           time.Duration
+		allowed             bool
+	}{
+		{"after rejection, not allowed", false, 0, models.StatusRejected, 365 * day, false},
+		{"after rejection, allowed", true, 0, models.StatusRejected, time.Hour, true},
+		{"after withdrawal, rejection not allowed", false, 0, models.StatusWithdrawn, time.Hour, true},
+		{"after disbursement", false, 0, models.StatusDisbursed, time.Hour, true},
+		{"inside the cooldown", true, 30, models.StatusRejected, 29 * day, false},
+		{"inside the cooldown after withdrawal", false, 30, models.StatusWithdrawn, day, false},
+		{"after the cooldown", true, 30, models.StatusRejected, 31 * day, true},
+		{"after the cooldown following withdrawal", false, 30, models.StatusWithdrawn, 30*day + time.Hour, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestService()
+			applicant, scheme := newTestPair(t, svc, tt.allowAfterRejection, tt.cooldownDays)
+			closedApplication(t, svc, applicant, scheme, tt.previous, tt.closedAgo)
+
+			err := apply(svc, applicant, scheme)
+			switch {
+			case tt.allowed && err != nil:
+				t.Errorf("reapplication = %v, want it accepted", err)
+			case !tt.allowed && !errors.Is(err, ErrReapplicationNotAllowed):
+				t.Errorf("reapplication = %v, want ErrReapplicationNotAllowed", err)
+			}
+		})
+	}
+}
+
+func TestReapplicationFollowsLatestApplication(t *testing.T) {
+	svc := newTestService()
+	applicant, scheme := newTestPair(t, svc, false, 0)
+	closedApplication(t, svc, applicant, scheme, models.StatusWithdrawn, 400*24*time.Hour)
+	closedApplication(t, svc, applicant, scheme, models.StatusRejected, 2*time.Hour)
+
+	err := apply(svc, applicant, scheme)
+	if !errors.Is(err, ErrReapplicationNotAllowed) || !strings.Contains(err.Error(), "rejected") {
+		t.Errorf("reapplication after a later rejection = %v, want ErrReapplicationNotAllowed naming the rejection", err)
+	}
+}
@@ -0,0 +1,107 @@
+package eligibility
+
+import (
+	"financial_assistance/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Input struct {
+	Applicant *models.Applicant
+	Criteria  *models.Criteria
+	// AsOf is the reference date for age-based criteria.
+	AsOf time.Time
+}
+
+type Rule interface {
+	Name() string
+	Evaluate(in Input) bool
+}
+
+type ruleFunc struct {
+	name string
+	fn   func(in Input) bool
+}
+
+func NewRule(name string, fn func(in Input) bool) Rule {
+	return ruleFunc{name: name, fn: fn}
+}
+
+func (r ruleFunc) Name() string {
+	return r.name
+}
+
+func (r ruleFunc) Evaluate(in Input) bool {
+	return r.fn(in)
+}
+
+// All passes only when every rule passes.
+func All(name string, rules ...Rule) Rule {
+	return NewRule(name, func(in Input) bool {
+		for _, rule := range rules {
+			if !rule.Evaluate(in) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Any passes when at least one rule passes.
+func Any(name string, rules ...Rule) Rule {
+	return NewRule(name, func(in Input) bool {
+		for _, rule := range rules {
+			if rule.Evaluate(in) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+type Result struct {
+	SchemeID uuid.UUID `json:"scheme_id"`
+	Eligible bool      `json:"eligible"`
+	Failed   []string  `json:"failed_criteria,omitempty"`
+}
+
+type Engine struct {
+	rules []Rule
+}
+
+func NewEngine(rules ...Rule) *Engine {
+	return &Engine{rules: rules}
+}
+
+func NewDefaultEngine() *Engine {
+	return NewEngine(DefaultRules()...)
+}
+
+func (e *Engine) Evaluate(applicant *models.Applicant, scheme *models.Scheme, asOf time.Time) Result {
+	in := Input{
+		Applicant: applicant,
+		Criteria:  &scheme.Criteria,
+		AsOf:      asOf,
+	}
+
+	result := Result{SchemeID: scheme.ID, Eligible: true}
+	for _, rule := range e.rules {
+		if !rule.Evaluate(in) {
+			result.Eligible = false
+			result.Failed = append(result.Failed, rule.Name())
+		}
+	}
+
+	return result
+}
+
+func (e *Engine) EligibleSchemes(applicant *models.Applicant, schemes []models.Scheme, asOf time.Time) []models.Scheme {
+	var eligible []models.Scheme
+	for i := range schemes {
+		if e.Evaluate(applicant, &schemes[i], asOf).Eligible {
+			eligible = append(eligible, schemes[i])
+		}
+	}
+	return eligible
+}
@@ -0,0 +1,92 @@
+package eligibility
+
+import "financial_assistance/internal/models"
+
+func DefaultRules() []Rule {
+	return []Rule{
+		EmploymentStatusRule(),
+		MaritalStatusRule(),
+		HasChildrenRule(),
+		ApplicantAgeRule(),
+		HouseholdMemberAgeRule(),
+		MaxHouseholdIncomeRule(),
+		MaxPerCapitaIncomeRule(),
+	}
+}
+
+func EmploymentStatusRule() Rule {
+	return NewRule("employment_status", func(in Input) bool {
+		return in.Criteria.EmploymentStatus == nil ||
+			*in.Criteria.EmploymentStatus == in.Applicant.EmploymentStatus
+	})
+}
+
+func MaritalStatusRule() Rule {
+	return NewRule("marital_status", func(in Input) bool {
+		return in.Criteria.MaritalStatus == nil ||
+			*in.Criteria.MaritalStatus == in.Applicant.MaritalStatus
+	})
+}
+
+func HasChildrenRule() Rule {
+	return NewRule("has_children", func(in Input) bool {
+		return in.Criteria.HasChildren == nil ||
+			*in.Criteria.HasChildren == hasChildren(in.Applicant)
+	})
+}
+
+func hasChildren(applicant *models.Applicant) bool {
+	for _, member := range applicant.HouseholdMembers {
+		if member.Relation.IsChild() {
+			return true
+		}
+	}
+	return false
+}
+
+func ApplicantAgeRule() Rule {
+	return NewRule("applicant_age", func(in Input) bool {
+		return ageWithin(in.Applicant.AgeAt(in.AsOf), in.Criteria.MinApplicantAge, in.Criteria.MaxApplicantAge)
+	})
+}
+
+// HouseholdMemberAgeRule requires at least one household member whose age
+// falls within the configured bounds.
+func HouseholdMemberAgeRule() Rule {
+	return NewRule("household_member_age", func(in Input) bool {
+		minAge, maxAge := in.Criteria.HouseholdMemberMinAge, in.Criteria.HouseholdMemberMaxAge
+		if minAge == nil && maxAge == nil {
+			return true
+		}
+		for i := range in.Applicant.HouseholdMembers {
+			if ageWithin(in.Applicant.HouseholdMembers[i].AgeAt(in.AsOf), minAge, maxAge) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func ageWithin(age int, minAge, maxAge *int) bool {
+	if minAge != nil && age < *minAge {
+		return false
+	}
+	if maxAge != nil && age > *maxAge {
+		return false
+	}
+	return true
+}
+
+func MaxHouseholdIncomeRule() Rule {
+	return NewRule("max_household_income", func(in Input) bool {
+		limit := in.Criteria.MaxHouseholdIncome
+		return limit == nil || in.Applicant.HouseholdIncome() <= *limit
+	})
+}
+
+func MaxPerCapitaIncomeRule() Rule {
+	return NewRule("max_per_capita_income", func(in Input) bool {
+		limit := in.Criteria.MaxPerCapitaIncome
+		return limit == nil || in.Applicant.PerCapitaIncome() <= *limit
+	})
+}
@@ -0,0 +1,158 @@
+package eligibility
+
+import (
+	"financial_assistance/internal/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+var asOf = time.Date(2025, time.June, 15, 0, 0, 0, 0, time.UTC)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// born returns the date of birth of someone who turns age on asOf.
+func born(age int) time.Time {
+	return asOf.AddDate(-age, 0, 0)
+}
+
+func member(relation models.Relation, age int, income float64) models.HouseholdMember {
+	return models.HouseholdMember{Relation: relation, DateOfBirth: born(age), MonthlyIncome: income}
+}
+
+type ruleTest struct {
+	name      string
+	applicant models.Applicant
+	criteria  models.Criteria
+	want      bool
+}
+
+func runRuleTests(t *testing.T, rule Rule, tests []ruleTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := Input{Applicant: &tt.applicant, Criteria: &tt.criteria, AsOf: asOf}
+			if got := rule.Evaluate(in); got != tt.want {
+				t.Errorf("%s = %v, want %v", rule.Name(), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmploymentStatusRule(t *testing.T) {
+	unemployed := models.Applicant{EmploymentStatus: models.EmploymentUnemployed}
+	runRuleTests(t, EmploymentStatusRule(), []ruleTest{
+		{"any", unemployed, models.Criteria{}, true},
+		{"match", unemployed, models.Criteria{EmploymentStatus: ptr(models.EmploymentUnemployed)}, true},
+		{"mismatch", unemployed, models.Criteria{EmploymentStatus: ptr(models.EmploymentEmployed)}, false},
+	})
+}
+
+func TestMaritalStatusRule(t *testing.T) {
+	married := models.Applicant{MaritalStatus: models.MaritalMarried}
+	runRuleTests(t, MaritalStatusRule(), []ruleTest{
+		{"any", married, models.Criteria{}, true},
+		{"match", married, models.Criteria{MaritalStatus: ptr(models.MaritalMarried)}, true},
+		{"mismatch", married, models.Criteria{MaritalStatus: ptr(models.MaritalSingle)}, false},
+	})
+}
+
+func TestHasChildrenRule(t *testing.T) {
+	childless := models.Applicant{HouseholdMembers: []models.HouseholdMember{member(models.RelationSpouse, 40, 0)}}
+	withSon := models.Applicant{HouseholdMembers: []models.HouseholdMember{member(models.RelationSon, 10, 0)}}
+	withDaughter := models.Applicant{HouseholdMembers: []models.HouseholdMember{member(models.RelationDaughter, 10, 0)}}
+
+	runRuleTests(t, HasChildrenRule(), []ruleTest{
+		{"any without children", childless, models.Criteria{}, true},
+		{"any with children", withSon, models.Criteria{}, true},
+		{"required and son", withSon, models.Criteria{HasChildren: ptr(true)}, true},
+		{"required and daughter", withDaughter, models.Criteria{HasChildren: ptr(true)}, true},
+		{"required and none", childless, models.Criteria{HasChildren: ptr(true)}, false},
+		{"excluded and none", childless, models.Criteria{HasChildren: ptr(false)}, true},
+		{"excluded and son", withSon, models.Criteria{HasChildren: ptr(false)}, false},
+		{"excluded and no household", models.Applicant{}, models.Criteria{HasChildren: ptr(false)}, true},
+	})
+}
+
+func TestApplicantAgeRule(t *testing.T) {
+	thirty := models.Applicant{DateOfBirth: born(30)}
+	// The day before the 30th birthday.
+	almostThirty := models.Applicant{DateOfBirth: born(30).AddDate(0, 0, 1)}
+
+	runRuleTests(t, ApplicantAgeRule(), []ruleTest{
+		{"no bounds", thirty, models.Criteria{}, true},
+		{"at minimum", thirty, models.Criteria{MinApplicantAge: ptr(30)}, true},
+		{"below minimum", almostThirty, models.Criteria{MinApplicantAge: ptr(30)}, false},
+		{"at maximum", thirty, models.Criteria{MaxApplicantAge: ptr(30)}, true},
+		{"above maximum", thirty, models.Criteria{MaxApplicantAge: ptr(29)}, false},
+		{"within range", thirty, models.Criteria{MinApplicantAge: ptr(18), MaxApplicantAge: ptr(65)}, true},
+	})
+}
+
+func TestHouseholdMemberAgeRule(t *testing.T) {
+	family := models.Applicant{HouseholdMembers: []models.HouseholdMember{
+		member(models.RelationSpouse, 40, 0),
+		member(models.RelationSon, 5, 0),
+	}}
+
+	runRuleTests(t, HouseholdMemberAgeRule(), []ruleTest{
+		{"no bounds", family, models.Criteria{}, true},
+		{"no bounds and no household", models.Applicant{}, models.Criteria{}, true},
+		{"one member within", family, models.Criteria{HouseholdMemberMaxAge: ptr(6)}, true},
+		{"none within", family, models.Criteria{HouseholdMemberMinAge: ptr(6), HouseholdMemberMaxAge: ptr(12)}, false},
+		{"bounded and no household", models.Applicant{}, models.Criteria{HouseholdMemberMaxAge: ptr(12)}, false},
+	})
+}
+
+func TestMaxHouseholdIncomeRule(t *testing.T) {
+	// 1000 + 500 + 0 = 1500 a month.
+	household := models.Applicant{MonthlyIncome: 1000, HouseholdMembers: []models.HouseholdMember{
+		member(models.RelationSpouse, 40, 500),
+		member(models.RelationSon, 5, 0),
+	}}
+
+	runRuleTests(t, MaxHouseholdIncomeRule(), []ruleTest{
+		{"not means-tested", household, models.Criteria{}, true},
+		{"at limit", household, models.Criteria{MaxHouseholdIncome: ptr(1500.0)}, true},
+		{"above limit", household, models.Criteria{MaxHouseholdIncome: ptr(1499.99)}, false},
+	})
+}
+
+func TestMaxPerCapitaIncomeRule(t *testing.T) {
+	// 1500 a month shared by three people is 500 each.
+	household := models.Applicant{MonthlyIncome: 1000, HouseholdMembers: []models.HouseholdMember{
+		member(models.RelationSpouse, 40, 500),
+		member(models.RelationSon, 5, 0),
+	}}
+
+	runRuleTests(t, MaxPerCapitaIncomeRule(), []ruleTest{
+		{"not means-tested", household, models.Criteria{}, true},
+		{"at limit", household, models.Criteria{MaxPerCapitaIncome: ptr(500.0)}, true},
+		{"above limit", household, models.Criteria{MaxPerCapitaIncome: ptr(499.0)}, false},
+		{"applicant alone", models.Applicant{MonthlyIncome: 600}, models.Criteria{MaxPerCapitaIncome: ptr(500.0)}, false},
+	})
+}
+
+func TestEngineReportsFailedRules(t *testing.T) {
+	applicant := &models.Applicant{
+		EmploymentStatus: models.EmploymentEmployed,
+		MaritalStatus:    models.MaritalSingle,
+		DateOfBirth:      born(30),
+	}
+	scheme := &models.Scheme{Criteria: models.Criteria{
+		EmploymentStatus: ptr(models.EmploymentUnemployed),
+		MaritalStatus:    ptr(models.MaritalSingle),
+		HasChildren:      ptr(true),
+	}}
+
+	result := NewDefaultEngine().Evaluate(applicant, scheme, asOf)
+	if result.Eligible {
+		t.Fatal("Eligible = true, want false")
+	}
+	want := []string{"employment_status", "has_children"}
+	if !slices.Equal(result.Failed, want) {
+		t.Errorf("Failed = %v, want %v", result.Failed, want)
+	}
+}
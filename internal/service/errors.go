@@ -0,0 +1,42 @@
+package service
+
+import (
+	"financial_assistance/internal/apperror"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidStatus     = apperror.Validation("invalid_status", "invalid application status")
+	ErrIllegalTransition = apperror.Conflict("illegal_transition", "illegal application status transition")
+
+	// ErrUnknownApplicant and ErrUnknownScheme are returned when a request
+	// body refers to a record that does not exist.
+	ErrUnknownApplicant = apperror.Unprocessable("unknown_applicant", "applicant does not exist")
+	ErrUnknownScheme    = apperror.Unprocessable("unknown_scheme", "scheme does not exist")
+
+	ErrDuplicateApplication    = apperror.Conflict("duplicate_application", "an active application already exists for this applicant and scheme")
+	ErrReapplicationNotAllowed = apperror.Conflict("reapplication_not_allowed", "reapplication to this scheme is not allowed")
+
+	ErrApplicantInUse = apperror.Conflict("applicant_in_use", "applicant has applications and cannot be deleted")
+	ErrSchemeInUse    = apperror.Conflict("scheme_in_use", "scheme has applications and cannot be deleted")
+
+	ErrIneligible = apperror.Unprocessable("not_eligible", "applicant is not eligible for this scheme")
+)
+
+// IneligibleError is returned when an applicant applies for a scheme whose
+// criteria they do not meet.
+type IneligibleError struct {
+	SchemeID uuid.UUID
+	Failed   []string
+}
+
+func (e *IneligibleError) Error() string {
+	return fmt.Sprintf("applicant is not eligible for scheme %s: failed %s", e.SchemeID, strings.Join(e.Failed, ", "))
+}
+
+func (e *IneligibleError) Unwrap() error {
+	return ErrIneligible.With("scheme_id", e.SchemeID).With("failed_criteria", e.Failed)
+}
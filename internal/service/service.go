@@ -2,8 +2,13 @@ package service
 
 import (
 	"context"
+	"errors"
+	"financial_assistance/internal/auth"
 	"financial_assistance/internal/models"
 	"financial_assistance/internal/repository"
+	"financial_assistance/internal/service/eligibility"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -12,6 +17,7 @@ type Service struct {
 	applicantRepo   repository.ApplicantRepository
 	schemeRepo      repository.SchemeRepository
 	applicationRepo repository.ApplicationRepository
+	eligibility     *eligibility.Engine
 }
 
 func NewService(
@@ -23,33 +29,431 @@ func NewService(
 		applicantRepo:   applicantRepo,
 		schemeRepo:      schemeRepo,
 		applicationRepo: applicationRepo,
+		eligibility:     eligibility.NewDefaultEngine(),
 	}
 }
 
-func (s *Service) GetAllApplicants(ctx context.Context) ([]models.Applicant, error) {
-	return s.applicantRepo.GetAllApplicants(ctx)
+func (s *Service) GetAllApplicants(ctx context.Context, filter repository.ApplicantFilter) ([]models.Applicant, string, error) {
+	if err := auth.Authorize(ctx, auth.ReadApplicants); err != nil {
+		return nil, "", err
+	}
+	return s.applicantRepo.GetAllApplicants(ctx, filter)
 }
 
 func (s *Service) CreateApplicant(ctx context.Context, applicant *models.Applicant) error {
+	if err := auth.Authorize(ctx, auth.WriteApplicants); err != nil {
+		return err
+	}
+	applicant.NationalID = models.NormalizeNationalID(applicant.NationalID)
+	if err := applicant.Validate(time.Now()); err != nil {
+		return err
+	}
+	if applicant.ID == uuid.Nil {
+		applicant.ID = newID()
+	}
+	assignMemberIDs(applicant.HouseholdMembers)
 	return s.applicantRepo.CreateApplicant(ctx, applicant)
 }
 
-func (s *Service) GetAllSchemes(ctx context.Context) ([]models.Scheme, error) {
-	return s.schemeRepo.GetAllSchemes(ctx)
+func (s *Service) GetApplicant(ctx context.Context, id uuid.UUID) (*models.Applicant, error) {
+	if err := auth.Authorize(ctx, auth.ReadApplicants); err != nil {
+		return nil, err
+	}
+	return s.applicantRepo.GetApplicant(ctx, id)
+}
+
+func (s *Service) UpdateApplicant(ctx context.Context, applicant *models.Applicant) error {
+	if err := auth.Authorize(ctx, auth.WriteApplicants); err != nil {
+		return err
+	}
+	applicant.NationalID = models.NormalizeNationalID(applicant.NationalID)
+	if err := applicant.Validate(time.Now()); err != nil {
+		return err
+	}
+	assignMemberIDs(applicant.HouseholdMembers)
+	return s.applicantRepo.UpdateApplicant(ctx, applicant)
+}
+
+func (s *Service) DeleteApplicant(ctx context.Context, id uuid.UUID, version int) error {
+	if err := auth.Authorize(ctx, auth.WriteApplicants); err != nil {
+		return err
+	}
+	err := s.applicantRepo.DeleteApplicant(ctx, id, version)
+	if errors.Is(err, repository.ErrReferenced) {
+		return ErrApplicantInUse
+	}
+	return err
+}
+
+func (s *Service) AddHouseholdMember(ctx context.Context, applicantID uuid.UUID, member *models.HouseholdMember, version int) (int, error) {
+	if err := auth.Authorize(ctx, auth.WriteApplicants); err != nil {
+		return 0, err
+	}
+	if err := member.Validate(time.Now()); err != nil {
+		return 0, err
+	}
+	if member.ID == uuid.Nil {
+		member.ID = newID()
+	}
+	return s.applicantRepo.AddHouseholdMember(ctx, applicantID, member, version)
+}
+
+// GetHouseholdMember returns the member together with the version of its
+// applicant.
+func (s *Service) GetHouseholdMember(ctx context.Context, applicantID, memberID uuid.UUID) (*models.HouseholdMember, int, error) {
+	if err := auth.Authorize(ctx, auth.ReadApplicants); err != nil {
+		return nil, 0, err
+	}
+	applicant, err := s.applicantRepo.GetApplicant(ctx, applicantID)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range applicant.HouseholdMembers {
+		if applicant.HouseholdMembers[i].ID == memberID {
+			return &applicant.HouseholdMembers[i], applicant.Version, nil
+		}
+	}
+	return nil, 0, repository.ErrHouseholdMemberNotFound
+}
+
+func (s *Service) UpdateHouseholdMember(ctx context.Context, applicantID uuid.UUID, member *models.HouseholdMember, version int) (int, error) {
+	if err := auth.Authorize(ctx, auth.WriteApplicants); err != nil {
+		return 0, err
+	}
+	if err := member.Validate(time.Now()); err != nil {
+		return 0, err
+	}
+	return s.applicantRepo.UpdateHouseholdMember(ctx, applicantID, member, version)
+}
+
+func (s *Service) DeleteHouseholdMember(ctx context.Context, applicantID, memberID uuid.UUID, version int) (int, error) {
+	if err := auth.Authorize(ctx, auth.WriteApplicants); err != nil {
+		return 0, err
+	}
+	return s.applicantRepo.DeleteHouseholdMember(ctx, applicantID, memberID, version)
+}
+
+func (s *Service) GetAllSchemes(ctx context.Context, filter repository.SchemeFilter) ([]models.Scheme, string, error) {
+	if err := auth.Authorize(ctx, auth.ReadSchemes); err != nil {
+		return nil, "", err
+	}
+	return s.schemeRepo.GetAllSchemes(ctx, filter)
+}
+
+func (s *Service) GetScheme(ctx context.Context, id uuid.UUID) (*models.Scheme, error) {
+	if err := auth.Authorize(ctx, auth.ReadSchemes); err != nil {
+		return nil, err
+	}
+	return s.schemeRepo.GetScheme(ctx, id)
+}
+
+func (s *Service) UpdateScheme(ctx context.Context, scheme *models.Scheme) error {
+	if err := auth.Authorize(ctx, auth.WriteSchemes); err != nil {
+		return err
+	}
+	scheme.Criteria.Normalize()
+	if err := scheme.Validate(); err != nil {
+		return err
+	}
+	assignBenefitIDs(scheme.Benefits)
+	return s.schemeRepo.UpdateScheme(ctx, scheme)
+}
+
+func (s *Service) DeleteScheme(ctx context.Context, id uuid.UUID, version int) error {
+	if err := auth.Authorize(ctx, auth.WriteSchemes); err != nil {
+		return err
+	}
+	err := s.schemeRepo.DeleteScheme(ctx, id, version)
+	if errors.Is(err, repository.ErrReferenced) {
+		return ErrSchemeInUse
+	}
+	return err
+}
+
+func (s *Service) UpdateCriteria(ctx context.Context, schemeID uuid.UUID, criteria *models.Criteria, version int) (int, error) {
+	if err := auth.Authorize(ctx, auth.WriteSchemes); err != nil {
+		return 0, err
+	}
+	criteria.Normalize()
+	if err := criteria.Validate(); err != nil {
+		return 0, err
+	}
+	return s.schemeRepo.UpdateCriteria(ctx, schemeID, criteria, version)
+}
+
+func (s *Service) AddBenefit(ctx context.Context, schemeID uuid.UUID, benefit *models.Benefit, version int) (int, error) {
+	if err := auth.Authorize(ctx, auth.WriteSchemes); err != nil {
+		return 0, err
+	}
+	if err := benefit.Validate(); err != nil {
+		return 0, err
+	}
+	if benefit.ID == uuid.Nil {
+		benefit.ID = newID()
+	}
+	return s.schemeRepo.AddBenefit(ctx, schemeID, benefit, version)
+}
+
+// GetBenefit returns the benefit together with the version of its scheme.
+func (s *Service) GetBenefit(ctx context.Context, schemeID, benefitID uuid.UUID) (*models.Benefit, int, error) {
+	if err := auth.Authorize(ctx, auth.ReadSchemes); err != nil {
+		return nil, 0, err
+	}
+	scheme, err := s.schemeRepo.GetScheme(ctx, schemeID)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range scheme.Benefits {
+		if scheme.Benefits[i].ID == benefitID {
+			return &scheme.Benefits[i], scheme.Version, nil
+		}
+	}
+	return nil, 0, repository.ErrBenefitNotFound
+}
+
+func (s *Service) DeleteBenefit(ctx context.Context, schemeID, benefitID uuid.UUID, version int) (int, error) {
+	if err := auth.Authorize(ctx, auth.WriteSchemes); err != nil {
+		return 0, err
+	}
+	return s.schemeRepo.DeleteBenefit(ctx, schemeID, benefitID, version)
+}
+
+func (s *Service) GetEligibleSchemes(ctx context.Context, applicantID uuid.UUID, asOf time.Time) ([]models.Scheme, error) {
+	if err := auth.Authorize(ctx, auth.ReadApplicants); err != nil {
+		return nil, err
+	}
+	applicant, err := s.applicantRepo.GetApplicant(ctx, applicantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var eligible []models.Scheme
+	filter := repository.SchemeFilter{Page: repository.Page{Limit: repository.MaxPageLimit}}
+	for {
+		schemes, next, err := s.schemeRepo.GetAllSchemes(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		eligible = append(eligible, s.eligibility.EligibleSchemes(applicant, schemes, asOf)...)
+		if next == "" {
+			return eligible, nil
+		}
+		filter.Cursor = next
+	}
+}
+
+// anonymousActor is recorded on application events when the context carries
+// no principal, which only happens outside of HTTP requests.
+const anonymousActor = "anonymous"
+
+// actor identifies the principal of ctx in the application audit trail.
+func actor(ctx context.Context) string {
+	if principal, ok := auth.FromContext(ctx); ok {
+		return principal.Subject()
+	}
+	return anonymousActor
 }
 
-func (s *Service) GetEligibleSchemes(ctx context.Context, applicantID uuid.UUID) ([]models.Scheme, error) {
-	return s.schemeRepo.GetEligibleSchemes(ctx, applicantID)
+type TransitionRequest struct {
+	Status     models.ApplicationStatus `json:"status"`
+	ReasonCode string                   `json:"reason_code"`
 }
 
 func (s *Service) CreateApplication(ctx context.Context, application *models.Application) error {
-	return s.applicationRepo.CreateApplication(ctx, application)
+	if err := auth.Authorize(ctx, auth.WriteApplications); err != nil {
+		return err
+	}
+	if application.Status == "" {
+		application.Status = models.StatusDraft
+	}
+	if !application.Status.Valid() {
+		return fmt.Errorf("%w: %q", ErrInvalidStatus, application.Status)
+	}
+	if !application.Status.IsInitial() {
+		return fmt.Errorf("%w: applications cannot be created as %q", ErrIllegalTransition, application.Status)
+	}
+
+	applicant, err := s.applicantRepo.GetApplicant(ctx, application.ApplicantID)
+	if errors.Is(err, repository.ErrApplicantNotFound) {
+		return fmt.Errorf("%w: %s", ErrUnknownApplicant, application.ApplicantID)
+	}
+	if err != nil {
+		return err
+	}
+
+	scheme, err := s.schemeRepo.GetScheme(ctx, application.SchemeID)
+	if errors.Is(err, repository.ErrSchemeNotFound) {
+		return fmt.Errorf("%w: %s", ErrUnknownScheme, application.SchemeID)
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	if result := s.eligibility.Evaluate(applicant, scheme, now); !result.Eligible {
+		return &IneligibleError{SchemeID: scheme.ID, Failed: result.Failed}
+	}
+
+	if err := s.checkReapplication(ctx, application, scheme, now); err != nil {
+		return err
+	}
+
+	if application.ID == uuid.Nil {
+		application.ID = newID()
+	}
+	application.CreatedAt = now
+	application.UpdatedAt = now
+
+	event := newApplicationEvent(application.ID, "", application.Status, actor(ctx), "", now)
+	err = s.applicationRepo.CreateApplication(ctx, application, event)
+	if errors.Is(err, repository.ErrActiveApplicationExists) {
+		return ErrDuplicateApplication
+	}
+	return err
+}
+
+// checkReapplication applies the scheme's reapplication policy to the
+// applicant's earlier applications for the same scheme.
+func (s *Service) checkReapplication(ctx context.Context, application *models.Application, scheme *models.Scheme, now time.Time) error {
+	previous, err := s.applicationRepo.GetApplicationsByApplicantAndScheme(ctx, application.ApplicantID, scheme.ID)
+	if err != nil {
+		return err
+	}
+
+	var last *models.Application
+	for i := range previous {
+		if !previous[i].Status.IsTerminal() {
+			return ErrDuplicateApplication
+		}
+		if last == nil || previous[i].UpdatedAt.After(last.UpdatedAt) {
+			last = &previous[i]
+		}
+	}
+	if last == nil {
+		return nil
+	}
+
+	if last.Status == models.StatusRejected && !scheme.AllowReapplyAfterRejection {
+		return fmt.Errorf("%w: previous application %s was rejected", ErrReapplicationNotAllowed, last.ID)
+	}
+
+	cooldownEnds := last.UpdatedAt.AddDate(0, 0, scheme.ReapplyCooldownDays)
+	if now.Before(cooldownEnds) {
+		return fmt.Errorf("%w before %s", ErrReapplicationNotAllowed, cooldownEnds.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+func (s *Service) TransitionApplication(ctx context.Context, id uuid.UUID, req TransitionRequest, version int) (*models.Application, error) {
+	if err := auth.Authorize(ctx, auth.WriteApplications); err != nil {
+		return nil, err
+	}
+	if !req.Status.Valid() {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidStatus, req.Status)
+	}
+
+	application, err := s.applicationRepo.GetApplication(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if application.Version != version {
+		return nil, repository.ErrVersionMismatch
+	}
+
+	if !application.Status.CanTransitionTo(req.Status) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrIllegalTransition, application.Status, req.Status)
+	}
+
+	event := newApplicationEvent(id, application.Status, req.Status, actor(ctx), req.ReasonCode, time.Now().UTC())
+	version, err = s.applicationRepo.UpdateApplicationStatus(ctx, event, version)
+	if errors.Is(err, repository.ErrStatusChanged) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrIllegalTransition, application.Status, req.Status)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	application.Status = req.Status
+	application.UpdatedAt = event.CreatedAt
+	application.Version = version
+	return application, nil
+}
+
+func (s *Service) GetApplication(ctx context.Context, id uuid.UUID) (*models.Application, error) {
+	if err := auth.Authorize(ctx, auth.ReadApplications); err != nil {
+		return nil, err
+	}
+	return s.applicationRepo.GetApplication(ctx, id)
+}
+
+// GetApplicationHistory returns the application's events together with its
+// version.
+func (s *Service) GetApplicationHistory(ctx context.Context, id uuid.UUID) ([]models.ApplicationEvent, int, error) {
+	if err := auth.Authorize(ctx, auth.ReadApplications); err != nil {
+		return nil, 0, err
+	}
+	application, err := s.applicationRepo.GetApplication(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	events, err := s.applicationRepo.GetApplicationEvents(ctx, id)
+	return events, application.Version, err
 }
 
-func (s *Service) GetAllApplications(ctx context.Context) ([]models.Application, error) {
-	return s.applicationRepo.GetAllApplications(ctx)
+func newApplicationEvent(applicationID uuid.UUID, from, to models.ApplicationStatus, actor, reasonCode string, at time.Time) *models.ApplicationEvent {
+	return &models.ApplicationEvent{
+		ID:            newID(),
+		ApplicationID: applicationID,
+		FromStatus:    from,
+		ToStatus:      to,
+		Actor:         actor,
+		ReasonCode:    reasonCode,
+		CreatedAt:     at,
+	}
+}
+
+func (s *Service) GetAllApplications(ctx context.Context, filter repository.ApplicationFilter) ([]models.Application, string, error) {
+	if err := auth.Authorize(ctx, auth.ReadApplications); err != nil {
+		return nil, "", err
+	}
+	return s.applicationRepo.GetAllApplications(ctx, filter)
 }
 
 func (s *Service) CreateScheme(ctx context.Context, scheme *models.Scheme) error {
+	if err := auth.Authorize(ctx, auth.WriteSchemes); err != nil {
+		return err
+	}
+	scheme.Criteria.Normalize()
+	if err := scheme.Validate(); err != nil {
+		return err
+	}
+	if scheme.ID == uuid.Nil {
+		scheme.ID = newID()
+	}
+	assignBenefitIDs(scheme.Benefits)
 	return s.schemeRepo.CreateScheme(ctx, scheme)
 }
+
+// newID returns a time-ordered UUIDv7, which keeps inserts at the end of
+// primary key indexes.
+func newID() uuid.UUID {
+	return uuid.Must(uuid.NewV7())
+}
+
+func assignMemberIDs(members []models.HouseholdMember) {
+	for i := range members {
+		if members[i].ID == uuid.Nil {
+			members[i].ID = newID()
+		}
+	}
+}
+
+func assignBenefitIDs(benefits []models.Benefit) {
+	for i := range benefits {
+		if benefits[i].ID == uuid.Nil {
+			benefits[i].ID = newID()
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"financial_assistance/internal/auth"
+	"financial_assistance/internal/models"
+	"financial_assistance/internal/repository"
+	"financial_assistance/internal/repository/memory"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func newTestService() *Service {
+	store := memory.NewStore()
+	return NewService(memory.NewApplicantRepo(store), memory.NewSchemeRepo(store), memory.NewApplicationRepo(store))
+}
+
+// as returns a context authenticated as a user with the given role.
+func as(role models.Role) context.Context {
+	principal := &auth.Principal{Kind: auth.PrincipalUser, ID: uuid.New(), Name: string(role), Role: role}
+	return auth.NewContext(context.Background(), principal)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestSchemeCriteriaAreNormalized(t *testing.T) {
+	svc := newTestService()
+	ctx := as(models.RoleAdmin)
+
+	scheme := &models.Scheme{
+		Name: "Retrenchment Assistance",
+		Criteria: models.Criteria{
+			EmploymentStatus: ptr(models.EmploymentStatus("")),
+			MaritalStatus:    ptr(models.MaritalStatus(" Married ")),
+		},
+	}
+	if err := svc.CreateScheme(ctx, scheme); err != nil {
+		t.Fatalf("CreateScheme: %v", err)
+	}
+
+	stored, err := svc.GetScheme(ctx, scheme.ID)
+	if err != nil {
+		t.Fatalf("GetScheme: %v", err)
+	}
+	if stored.Criteria.EmploymentStatus != nil {
+		t.Errorf("employment_status = %q, want nil for an empty criterion", *stored.Criteria.EmploymentStatus)
+	}
+	if got := stored.Criteria.MaritalStatus; got == nil || *got != models.MaritalMarried {
+		t.Errorf("marital_status = %v, want %q", got, models.MaritalMarried)
+	}
+
+	criteria := &models.Criteria{MaritalStatus: ptr(models.MaritalStatus(""))}
+	if _, err := svc.UpdateCriteria(ctx, scheme.ID, criteria, stored.Version); err != nil {
+		t.Fatalf("UpdateCriteria: %v", err)
+	}
+	stored, err = svc.GetScheme(ctx, scheme.ID)
+	if err != nil {
+		t.Fatalf("GetScheme: %v", err)
+	}
+	if stored.Criteria.MaritalStatus != nil {
+		t.Errorf("marital_status = %q after update, want nil for an empty criterion", *stored.Criteria.MaritalStatus)
+	}
+}
+
+func TestServiceChecksPermissions(t *testing.T) {
+	id := uuid.New()
+	calls := []struct {
+		name       string
+		permission auth.Permission
+		call       func(ctx context.Context, svc *Service) error
+	}{
+		{"GetAllApplicants", auth.ReadApplicants, func(ctx context.Context, svc *Service) error {
+			_, _, err := svc.GetAllApplicants(ctx, repository.ApplicantFilter{})
+			return err
+		}},
+		{"CreateApplicant", auth.WriteApplicants, func(ctx context.Context, svc *Service) error {
+			return svc.CreateApplicant(ctx, &models.Applicant{})
+		}},
+		{"DeleteApplicant", auth.WriteApplicants, func(ctx context.Context, svc *Service) error {
+			return svc.DeleteApplicant(ctx, id, 1)
+		}},
+		{"GetScheme", auth.ReadSchemes, func(ctx context.Context, svc *Service) error {
+			_, err := svc.GetScheme(ctx, id)
+			return err
+		}},
+		{"CreateScheme", auth.WriteSchemes, func(ctx context.Context, svc *Service) error {
+			return svc.CreateScheme(ctx, &models.Scheme{Name: "Universal Grant"})
+		}},
+		{"UpdateScheme", auth.WriteSchemes, func(ctx context.Context, svc *Service) error {
+			return svc.UpdateScheme(ctx, &models.Scheme{ID: id, Name: "Universal Grant", Version: 1})
+		}},
+		{"UpdateCriteria", auth.WriteSchemes, func(ctx context.Context, svc *Service) error {
+			_, err := svc.UpdateCriteria(ctx, id, &models.Criteria{}, 1)
+			return err
+		}},
+		{"AddBenefit", auth.WriteSchemes, func(ctx context.Context, svc *Service) error {
+			_, err := svc.AddBenefit(ctx, id, &models.Benefit{Name: "Vouchers"}, 1)
+			return err
+		}},
+		{"DeleteScheme", auth.WriteSchemes, func(ctx context.Context, svc *Service) error {
+			return svc.DeleteScheme(ctx, id, 1)
+		}},
+		{"GetApplication", auth.ReadApplications, func(ctx context.Context, svc *Service) error {
+			_, err := svc.GetApplication(ctx, id)
+			return err
+		}},
+		{"CreateApplication", auth.WriteApplications, func(ctx context.Context, svc *Service) error {
+			return svc.CreateApplication(ctx, &models.Application{ApplicantID: id, SchemeID: id})
+		}},
+	}
+
+	for _, c := range calls {
+		for _, role := range models.Roles {
+			t.Run(c.name+"/"+string(role), func(t *testing.T) {
+				err := c.call(as(role), newTestService())
+				if forbidden := errors.Is(err, auth.ErrForbidden); forbidden == auth.Allowed(role, c.permission) {
+					t.Errorf("%s as %s = %v, want forbidden %v", c.name, role, err, !auth.Allowed(role, c.permission))
+				}
+			})
+		}
+		t.Run(c.name+"/anonymous", func(t *testing.T) {
+			if err := c.call(context.Background(), newTestService()); !errors.Is(err, auth.ErrUnauthenticated) {
+				t.Errorf("%s without a principal = %v, want ErrUnauthenticated", c.name, err)
+			}
+		})
+	}
+}
+
+func TestSchemesAreWrittenOnlyByAdmins(t *testing.T) {
+	svc := newTestService()
+	scheme := &models.Scheme{Name: "Universal Grant"}
+	if err := svc.CreateScheme(as(models.RoleAdmin), scheme); err != nil {
+		t.Fatalf("CreateScheme as admin: %v", err)
+	}
+
+	if err := svc.CreateScheme(as(models.RoleAuditor), &models.Scheme{Name: "Another Grant"}); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("CreateScheme as auditor = %v, want ErrForbidden", err)
+	}
+	update := *scheme
+	update.Name = "Renamed Grant"
+	if err := svc.UpdateScheme(as(models.RoleCaseworker), &update); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("UpdateScheme as caseworker = %v, want ErrForbidden", err)
+	}
+	if _, err := svc.AddBenefit(as(models.RoleCaseworker), scheme.ID, &models.Benefit{Name: "Vouchers"}, scheme.Version); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("AddBenefit as caseworker = %v, want ErrForbidden", err)
+	}
+
+	stored, err := svc.GetScheme(as(models.RoleCaseworker), scheme.ID)
+	if err != nil {
+		t.Fatalf("GetScheme as caseworker: %v", err)
+	}
+	if stored.Name != "Universal Grant" || len(stored.Benefits) != 0 || stored.Version != 1 {
+		t.Errorf("scheme = %q with %d benefits at version %d, want it unchanged", stored.Name, len(stored.Benefits), stored.Version)
+	}
+}